@@ -1,10 +1,11 @@
 package main
 
 import (
-	"bufio"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
@@ -88,7 +89,11 @@ func main() {
 		usernameFile = flag.String("username", "", "从文件读取用户名列表，一行一个用户名")
 		passwordFile = flag.String("password", "", "从文件读取密码列表，一行一个密码")
 		chromePath   = flag.String("path", "", "Chrome浏览器可执行文件路径（可选，不指定则自动检测）")
+		profile      = flag.String("profile", "", "设备/区域模拟配置档名称（覆盖配置文件中的default_profile）")
 		analyze      = flag.Bool("analyze", false, "仅分析页面，不执行爆破")
+		jsonOutput   = flag.Bool("json", false, "以JSON格式输出分析结果（配合-analyze使用）")
+		handoff      = flag.Bool("handoff", false, "登录成功后在可见的Chrome窗口中恢复会话，便于继续手工测试")
+		record       = flag.Bool("record", false, "录制每次登录尝试（GIF动图或PNG帧序列，见配置文件results.recording）")
 		debug        = flag.Bool("debug", false, "调试模式，显示浏览器窗口和详细操作过程")
 		help         = flag.Bool("help", false, "显示帮助信息")
 	)
@@ -124,6 +129,22 @@ func main() {
 		fmt.Printf("✅ 使用指定的Chrome路径: %s\n", *chromePath)
 	}
 
+	// 如果指定了模拟配置档，设置为默认配置档
+	if *profile != "" {
+		if _, ok := cfg.Emulation.Profiles[*profile]; !ok {
+			fmt.Printf("未找到模拟配置档: %s\n", *profile)
+			os.Exit(1)
+		}
+		cfg.Emulation.DefaultProfile = *profile
+		fmt.Printf("✅ 使用模拟配置档: %s\n", *profile)
+	}
+
+	// 如果指定了录屏，开启每次尝试的录制
+	if *record {
+		cfg.Results.Recording.Enabled = true
+		fmt.Printf("🎬 已开启录屏，保存格式: %s\n", cfg.Results.Recording.Format)
+	}
+
 	// 从文件加载用户名和密码（如果指定）
 	if *usernameFile != "" {
 		usernames, err := readFileLines(*usernameFile)
@@ -208,6 +229,9 @@ func main() {
 		urls = []string{*targetURL}
 	}
 
+	// 记录各目标依赖的统一认证服务，共用同一IdP的目标只爆破一次
+	idpRegistry := detector.NewIdPRegistry()
+
 	// 处理每个URL
 	for i, url := range urls {
 		if len(urls) > 1 {
@@ -216,7 +240,38 @@ func main() {
 			fmt.Println(strings.Repeat("=", 70))
 		}
 
-		// 导航到目标URL
+		// 上一个目标处理过程中浏览器崩溃时先重启
+		if !browserInstance.Alive() {
+			util.LogWarn(fmt.Sprintf("💥 浏览器不可用(%s)，正在重启", browserInstance.CrashReason()))
+			if err := browserInstance.Restart(); err != nil {
+				util.LogError(fmt.Sprintf("重启浏览器失败: %v", err))
+				os.Exit(1)
+			}
+		}
+
+		// 应用该目标的主机名解析覆盖（规则变化时会重启浏览器）
+		targetConfig := cfg.GetTargetConfig(url)
+		if err := browserInstance.SetHostResolver(targetConfig.Resolve); err != nil {
+			util.LogError(fmt.Sprintf("应用主机解析覆盖失败: %v", err))
+			continue
+		}
+		for _, host := range sortedKeys(targetConfig.Resolve) {
+			fmt.Printf("🔀 解析覆盖: %s -> %s\n", host, targetConfig.Resolve[host])
+		}
+
+		// 应用该目标的设备/区域模拟配置档
+		profileName, emulationProfile := cfg.GetEmulationProfile(url)
+		if profileName != "" && emulationProfile == nil {
+			util.LogWarn(fmt.Sprintf("未找到模拟配置档 %s，使用默认浏览器设置", profileName))
+		}
+		if err := browserInstance.ApplyEmulation(profileName, emulationProfile); err != nil {
+			util.LogWarn(fmt.Sprintf("应用模拟配置档 %s 失败，使用默认浏览器设置: %v", profileName, err))
+		} else if emulationProfile != nil {
+			fmt.Printf("📱 模拟配置档: %s\n", profileName)
+		}
+
+		// 导航到目标URL，记录完整的重定向链
+		browserInstance.ResetNavigationLog()
 		if err := browserInstance.NavigateTo(url); err != nil {
 			util.LogError(fmt.Sprintf("导航到目标URL失败: %v", err))
 			continue
@@ -233,11 +288,29 @@ func main() {
 				continue
 			}
 
+			if analysis.IdP != nil {
+				idpRegistry.Register(url, analysis.IdP)
+			}
+
 			// 输出分析结果
-			printAnalysisResult(analysis)
+			if *jsonOutput {
+				printAnalysisJSON(analysis)
+			} else {
+				printAnalysisResult(analysis)
+			}
 			continue
 		}
 
+		// 识别统一认证服务，同一IdP已在其他目标上完成测试时跳过
+		idp := pageDetector.DetectIdentityProvider()
+		if idp != nil {
+			fmt.Printf("🔐 该目标通过 %s 认证: %s\n", idp.Product, idp.Key)
+			if first := idpRegistry.Register(url, idp); first != "" && cfg.Bruteforce.DedupeIdP {
+				fmt.Printf("🔁 该IdP已在目标 %s 上测试过，跳过\n", first)
+				continue
+			}
+		}
+
 		// 创建状态显示器和进度感知日志器
 		statusDisplay := util.NewStatusDisplay()
 		progressLogger := util.NewProgressAwareLogger(statusDisplay)
@@ -259,33 +332,106 @@ func main() {
 			continue
 		}
 
+		// 凭据全部尝试完或找到有效凭据后，才把该目标记为此IdP的测试代表
+		if idp != nil && result.Completed {
+			idpRegistry.MarkTested(url, idp)
+		}
+
 		// 输出结果
+		result.IdP = idp
 		printBruteForceResult(result)
+
+		// 将登录成功的会话移交给可见浏览器
+		if *handoff && result.Success {
+			handoffSession(browserInstance, result)
+		}
+	}
+
+	printIdPRelations(idpRegistry)
+}
+
+// printIdPRelations 输出各统一认证服务及通过它认证的目标
+func printIdPRelations(registry *detector.IdPRegistry) {
+	relations := registry.Relations()
+	if len(relations) == 0 {
+		return
+	}
+
+	util.LogInfo("=== 统一认证服务 ===")
+	for _, relation := range relations {
+		util.LogInfo(fmt.Sprintf("%s (%s)", relation.IdP.Product, relation.IdP.Key))
+		for i, app := range relation.Apps {
+			switch {
+			case app == relation.Tested:
+				util.LogInfo(fmt.Sprintf("  • %s（已测试）", app))
+			case i == 0:
+				util.LogInfo(fmt.Sprintf("  • %s", app))
+			default:
+				util.LogInfo(fmt.Sprintf("  • %s（共用该IdP）", app))
+			}
+		}
+	}
+}
+
+// handoffSession 在可见的Chrome窗口中恢复会话，等待测试人员关闭窗口
+func handoffSession(browserInstance *browser.Browser, result *bruteforce.BruteForceResult) {
+	if result.Session == nil {
+		util.LogWarn("未采集到会话状态，无法移交")
+		return
+	}
+
+	visible, err := browserInstance.Handoff(result.Session)
+	if err != nil {
+		util.LogError(fmt.Sprintf("移交会话失败: %v", err))
+		return
+	}
+	defer visible.Close()
+
+	fmt.Printf("\n🖐️  已在可见浏览器中打开登录后的页面: %s\n", result.Session.URL)
+	fmt.Printf("   用户名: %s，共恢复 %d 个Cookie\n", result.Username, len(result.Session.Cookies))
+	fmt.Println("   关闭浏览器窗口后继续...")
+	<-visible.Done()
+}
+
+// printAnalysisJSON 以JSON格式输出分析结果
+func printAnalysisJSON(analysis *detector.PageAnalysis) {
+	data, err := json.MarshalIndent(analysis, "", "  ")
+	if err != nil {
+		util.LogError(fmt.Sprintf("序列化分析结果失败: %v", err))
+		return
+	}
+	fmt.Println(string(data))
+}
+
+// sortedKeys 返回按字母排序的map键
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
 	}
+	sort.Strings(keys)
+	return keys
 }
 
-// readFileLines 从文件中读取行，去除空行和注释
+// readFileLines 从文件中读取行，去除空行和注释；GBK、UTF-16等编码的文件会先转换为UTF-8
 func readFileLines(filename string) ([]string, error) {
-	file, err := os.Open(filename)
+	text, charset, err := util.ReadTextFile(filename)
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
+	if charset != "UTF-8" {
+		util.LogInfo(fmt.Sprintf("文件 %s 编码为 %s，已转换为UTF-8", filename, charset))
+	}
 
 	var lines []string
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
 		// 跳过空行和注释行
 		if line != "" && !strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "//") {
 			lines = append(lines, line)
 		}
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, err
-	}
-
 	return lines, nil
 }
 
@@ -307,8 +453,12 @@ func showHelp() {
 	fmt.Println("  -username string   从文件读取用户名列表，一行一个用户名")
 	fmt.Println("  -password string   从文件读取密码列表，一行一个密码")
 	fmt.Println("  -path string       Chrome浏览器可执行文件路径（可选，不指定则自动检测）")
+	fmt.Println("  -profile string    设备/区域模拟配置档名称（见配置文件emulation.profiles）")
 	fmt.Println("  -config string     配置文件路径 (默认: config/config.yaml)")
 	fmt.Println("  -analyze           仅分析页面，不执行爆破")
+	fmt.Println("  -json              以JSON格式输出分析结果（配合-analyze使用）")
+	fmt.Println("  -handoff           登录成功后在可见的Chrome窗口中恢复会话，继续手工测试")
+	fmt.Println("  -record            录制每次登录尝试，保存为GIF动图或PNG帧序列")
 	fmt.Println("  -debug             调试模式，显示浏览器窗口和详细操作过程")
 	fmt.Println("  -help              显示此帮助信息")
 	fmt.Println()
@@ -331,6 +481,9 @@ func showHelp() {
 	fmt.Println("  # 指定Chrome浏览器路径")
 	fmt.Println("  ./chrome_auto_login -url \"http://example.com/login\" -path \"/path/to/chrome\"")
 	fmt.Println()
+	fmt.Println("  # 录制每次登录尝试（排查异常行为）")
+	fmt.Println("  ./chrome_auto_login -url \"http://example.com/login\" -record")
+	fmt.Println()
 	fmt.Println("  # 仅分析页面")
 	fmt.Println("  ./chrome_auto_login -url \"http://example.com/login\" -analyze")
 	fmt.Println()
@@ -358,8 +511,27 @@ func printAnalysisResult(analysis *detector.PageAnalysis) {
 	util.LogInfo(fmt.Sprintf("页面标题: %s", analysis.Title))
 	util.LogInfo(fmt.Sprintf("页面URL: %s", analysis.URL))
 	util.LogInfo(fmt.Sprintf("是否为登录页面: %t (置信度: %.2f)", analysis.IsLogin, analysis.Confidence))
-	util.LogInfo(fmt.Sprintf("页面编码: %s", analysis.Encoding))
+	if analysis.EncodingSource != "" {
+		util.LogInfo(fmt.Sprintf("页面编码: %s (来源: %s)", analysis.Encoding, analysis.EncodingSource))
+	} else {
+		util.LogInfo(fmt.Sprintf("页面编码: %s", analysis.Encoding))
+	}
+	if analysis.Profile != "" {
+		util.LogInfo(fmt.Sprintf("模拟配置档: %s", analysis.Profile))
+	}
+	for _, host := range sortedKeys(analysis.Resolve) {
+		util.LogInfo(fmt.Sprintf("解析覆盖: %s -> %s", host, analysis.Resolve[host]))
+	}
 	util.LogInfo(fmt.Sprintf("分析用时: %v", analysis.LoadTime))
+	if idp := analysis.IdP; idp != nil {
+		util.LogInfo(fmt.Sprintf("统一认证服务: %s (%s)", idp.Product, idp.Key))
+		for _, evidence := range idp.Evidence {
+			util.LogInfo(fmt.Sprintf("  %s", evidence))
+		}
+	}
+	if render := analysis.Render; render != nil {
+		util.LogInfo(fmt.Sprintf("渲染等待: %s (%dms, DOM变化 %d 次)", render.Reason, render.ElapsedMs, render.Mutations))
+	}
 
 	// 显示响应头信息
 	if len(analysis.ResponseHeaders) > 0 {
@@ -369,6 +541,47 @@ func printAnalysisResult(analysis *detector.PageAnalysis) {
 		}
 	}
 
+	// 显示TLS证书信息
+	if tls := analysis.TLS; tls != nil {
+		util.LogInfo("TLS证书信息:")
+		util.LogInfo(fmt.Sprintf("  协议: %s (%s)", tls.Protocol, tls.Cipher))
+		util.LogInfo(fmt.Sprintf("  主题: %s", tls.Subject))
+		util.LogInfo(fmt.Sprintf("  颁发者: %s", tls.Issuer))
+		util.LogInfo(fmt.Sprintf("  有效期: %s ~ %s", tls.ValidFrom.Format("2006-01-02"), tls.ValidTo.Format("2006-01-02")))
+		if len(tls.SANs) > 0 {
+			util.LogInfo(fmt.Sprintf("  SAN: %s", strings.Join(tls.SANs, ", ")))
+		}
+		if tls.HasCertificateError() {
+			util.LogWarn(fmt.Sprintf("  ⚠️  证书问题: %s", strings.Join(tls.CertificateProblems(), ", ")))
+		}
+	}
+
+	// 显示控制台消息和JavaScript异常
+	if len(analysis.Console) > 0 {
+		util.LogInfo(fmt.Sprintf("控制台消息 (%d条):", len(analysis.Console)))
+		for _, msg := range analysis.Console {
+			if msg.Level == "error" || msg.Level == "exception" {
+				util.LogWarn(fmt.Sprintf("  %s", msg))
+			} else {
+				util.LogInfo(fmt.Sprintf("  %s", msg))
+			}
+		}
+	}
+
+	// 显示得分明细
+	if score := analysis.Score; score != nil {
+		util.LogInfo(fmt.Sprintf("得分明细 (置信度 %.2f / 阈值 %.2f):", score.Confidence, score.Threshold))
+		for _, category := range score.Categories {
+			util.LogInfo(fmt.Sprintf("  %-8s 得分 %.2f × 权重 %.2f = %.3f", category.Category, category.Score, category.Weight, category.Contribution))
+			for _, match := range category.Matches {
+				util.LogInfo(fmt.Sprintf("    %+.2f  %s", match.Score, match.Rule))
+			}
+		}
+		if score.ConfigRule != "" {
+			util.LogInfo(fmt.Sprintf("  命中配置规则: %s", score.ConfigRule))
+		}
+	}
+
 	// 显示检测到的特征
 	if len(analysis.DetectedFeatures) > 0 {
 		util.LogInfo("检测到的页面特征:")
@@ -377,9 +590,23 @@ func printAnalysisResult(analysis *detector.PageAnalysis) {
 		}
 	}
 
+	// 显示表单候选
+	if len(analysis.FormCandidates) > 0 {
+		util.LogInfo(fmt.Sprintf("表单候选 (%d个):", len(analysis.FormCandidates)))
+		for _, candidate := range analysis.FormCandidates {
+			util.LogInfo(fmt.Sprintf("  %.2f  <%s> %s", candidate.Score, candidate.Tag, candidate.Selector))
+			for _, reason := range candidate.Reasons {
+				util.LogInfo(fmt.Sprintf("    %s", reason))
+			}
+		}
+	}
+
 	// 显示表单元素
 	if analysis.FormElements != nil {
 		util.LogInfo("检测到的表单元素:")
+		if analysis.FormElements.FormSelector != "" {
+			util.LogInfo(fmt.Sprintf("  所属表单: %s", analysis.FormElements.FormSelector))
+		}
 		if analysis.FormElements.UsernameSelector != "" {
 			util.LogInfo(fmt.Sprintf("  用户名输入框: %s", analysis.FormElements.UsernameSelector))
 		}
@@ -395,6 +622,12 @@ func printAnalysisResult(analysis *detector.PageAnalysis) {
 		if analysis.FormElements.SubmitSelector != "" {
 			util.LogInfo(fmt.Sprintf("  提交按钮: %s", analysis.FormElements.SubmitSelector))
 		}
+		if analysis.FormElements.MultiStep {
+			util.LogInfo(fmt.Sprintf("  分步登录下一步按钮: %s（密码框在下一步出现）", analysis.FormElements.NextSelector))
+		}
+		for _, field := range analysis.FormElements.Honeypots {
+			util.LogWarn(fmt.Sprintf("  🍯 疑似蜜罐字段: %s (%s)", field.Selector, strings.Join(field.Reasons, ", ")))
+		}
 
 		// 显示验证码检测结果
 		if analysis.FormElements.HasCaptcha && analysis.FormElements.CaptchaInfo != nil {
@@ -433,12 +666,28 @@ func printBruteForceResult(result *bruteforce.BruteForceResult) {
 		util.LogInfo(fmt.Sprintf("用户名: %s", result.Username))
 		util.LogInfo(fmt.Sprintf("密码: %s", result.Password))
 		util.LogInfo(fmt.Sprintf("目标URL: %s", result.URL))
+		if result.IdP != nil {
+			util.LogInfo(fmt.Sprintf("统一认证服务: %s (%s)", result.IdP.Product, result.IdP.Key))
+		}
 
-		// 保存截图
-		if len(result.Screenshot) > 0 {
-			if err := os.WriteFile("success_screenshot.png", result.Screenshot, 0644); err == nil {
-				util.LogInfo("成功截图已保存: success_screenshot.png")
-			}
+		if result.Outcome == bruteforce.OutcomeSuspected {
+			util.LogWarn("结果类型: 疑似成功（未找到明确的成功标识，请人工确认）")
+		}
+
+		// 截图路径
+		if result.ScreenshotPath != "" {
+			util.LogInfo(fmt.Sprintf("成功截图已保存: %s", result.ScreenshotPath))
+		}
+
+		// 证据包路径
+		if result.EvidencePath != "" {
+			util.LogInfo(fmt.Sprintf("证据包已保存: %s", result.EvidencePath))
+		}
+		if result.RecordingPath != "" {
+			util.LogInfo(fmt.Sprintf("录屏已保存: %s", result.RecordingPath))
+		}
+		if result.SessionPath != "" {
+			util.LogInfo(fmt.Sprintf("会话已导出: %s", result.SessionPath))
 		}
 	} else {
 		util.LogFailure("❌ 爆破失败")
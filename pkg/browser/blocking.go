@@ -0,0 +1,156 @@
+package browser
+
+import (
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+
+	"github.com/cyberspacesec/chrome_auto_login/util"
+)
+
+// resourceTypes 配置中可使用的资源类型（不区分大小写）
+var resourceTypes = []network.ResourceType{
+	network.ResourceTypeStylesheet,
+	network.ResourceTypeImage,
+	network.ResourceTypeMedia,
+	network.ResourceTypeFont,
+	network.ResourceTypeScript,
+	network.ResourceTypeTextTrack,
+	network.ResourceTypeXHR,
+	network.ResourceTypeFetch,
+	network.ResourceTypePrefetch,
+	network.ResourceTypeEventSource,
+	network.ResourceTypeWebSocket,
+	network.ResourceTypeManifest,
+	network.ResourceTypePing,
+	network.ResourceTypeOther,
+}
+
+// requestBlocker 基于Fetch域的请求拦截规则
+type requestBlocker struct {
+	patterns []*fetch.RequestPattern
+	allow    []*regexp.Regexp
+	blocked  int64
+}
+
+// newRequestBlocker 根据配置创建请求拦截器，未启用或没有规则时返回nil
+func (b *Browser) newRequestBlocker() *requestBlocker {
+	cfg := b.config.Browser.Blocking
+	if !cfg.Enabled {
+		return nil
+	}
+
+	blocker := &requestBlocker{}
+	for _, name := range cfg.ResourceTypes {
+		resourceType, ok := parseResourceType(name)
+		if !ok {
+			b.logger.Warnf("未知的资源类型: %s", name)
+			continue
+		}
+		blocker.patterns = append(blocker.patterns, &fetch.RequestPattern{
+			URLPattern:   "*",
+			ResourceType: resourceType,
+			RequestStage: fetch.RequestStageRequest,
+		})
+	}
+	for _, pattern := range cfg.URLPatterns {
+		if pattern = strings.TrimSpace(pattern); pattern == "" {
+			continue
+		}
+		blocker.patterns = append(blocker.patterns, &fetch.RequestPattern{
+			URLPattern:   pattern,
+			RequestStage: fetch.RequestStageRequest,
+		})
+	}
+	for _, pattern := range cfg.AllowPatterns {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			blocker.allow = append(blocker.allow, util.WildcardToRegexp(pattern))
+		}
+	}
+
+	if len(blocker.patterns) == 0 {
+		return nil
+	}
+	return blocker
+}
+
+// enableBlocking 启用请求拦截。重启浏览器时会再次调用，事件回调可能同时读取拦截器，需持有锁替换
+func (b *Browser) enableBlocking() error {
+	blocker := b.newRequestBlocker()
+	b.mu.Lock()
+	b.blocker = blocker
+	b.mu.Unlock()
+	if blocker == nil {
+		return nil
+	}
+
+	cfg := b.config.Browser.Blocking
+	b.logger.Infof("🚫 已启用资源拦截: 类型=%s, URL规则=%d条, 白名单=%d条",
+		strings.Join(cfg.ResourceTypes, ","), len(cfg.URLPatterns), len(cfg.AllowPatterns))
+
+	return chromedp.Run(b.ctx, fetch.Enable().WithPatterns(blocker.patterns))
+}
+
+// currentBlocker 获取当前的请求拦截器
+func (b *Browser) currentBlocker() *requestBlocker {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.blocker
+}
+
+// handleRequestPaused 处理被拦截的请求：白名单放行，其余直接失败
+func (b *Browser) handleRequestPaused(ev *fetch.EventRequestPaused) {
+	blocker := b.currentBlocker()
+	if blocker == nil {
+		return
+	}
+
+	ctx := b.ctx
+	var action chromedp.Action
+	if blocker.allowed(ev.Request.URL) {
+		action = fetch.ContinueRequest(ev.RequestID)
+	} else {
+		atomic.AddInt64(&blocker.blocked, 1)
+		action = fetch.FailRequest(ev.RequestID, network.ErrorReasonBlockedByClient)
+	}
+
+	// 事件回调中不能直接执行CDP命令
+	go func() {
+		if err := chromedp.Run(ctx, action); err != nil {
+			b.logger.Debugf("处理拦截请求失败: %v", err)
+		}
+	}()
+}
+
+// BlockedRequests 获取已拦截的请求数
+func (b *Browser) BlockedRequests() int64 {
+	blocker := b.currentBlocker()
+	if blocker == nil {
+		return 0
+	}
+	return atomic.LoadInt64(&blocker.blocked)
+}
+
+// allowed 判断请求是否在白名单中
+func (r *requestBlocker) allowed(url string) bool {
+	for _, re := range r.allow {
+		if re.MatchString(url) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseResourceType 解析资源类型名称
+func parseResourceType(name string) (network.ResourceType, bool) {
+	for _, resourceType := range resourceTypes {
+		if strings.EqualFold(string(resourceType), strings.TrimSpace(name)) {
+			return resourceType, true
+		}
+	}
+	return "", false
+}
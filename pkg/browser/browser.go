@@ -4,9 +4,15 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/cdproto/security"
+	"github.com/chromedp/cdproto/target"
 	"github.com/chromedp/chromedp"
 	"github.com/sirupsen/logrus"
 
@@ -19,47 +25,65 @@ type Browser struct {
 	cancel context.CancelFunc
 	config *config.Config
 	logger *logrus.Logger
+
+	// 事件监听记录的页面状态
+	mu          sync.Mutex
+	navigation  []NavigationHop
+	navIndex    map[network.RequestID]int
+	dialogs     []string
+	console     []ConsoleMessage
+	openedTabs  []target.ID
+	tabContexts map[target.ID]context.Context
+	tabCancels  map[target.ID]context.CancelFunc
+	tls         *TLSInfo
+	certState   *security.CertificateSecurityState
+	document    *DocumentResponse
+	crashReason string
+
+	// 当前应用的模拟配置档
+	profileName string
+	profile     *config.EmulationProfile
+
+	// 主机名解析覆盖（通过 --host-resolver-rules 传给Chrome）
+	resolve map[string]string
+
+	// 静态资源拦截
+	blocker *requestBlocker
+
+	// 录屏
+	recording bool
+	frames    []ScreencastFrame
 }
 
 // NewBrowser 创建新的浏览器实例
 func NewBrowser(cfg *config.Config, logger *logrus.Logger) *Browser {
 	return &Browser{
-		config: cfg,
-		logger: logger,
+		config:      cfg,
+		logger:      logger,
+		navIndex:    make(map[network.RequestID]int),
+		tabContexts: make(map[target.ID]context.Context),
+		tabCancels:  make(map[target.ID]context.CancelFunc),
 	}
 }
 
 // Start 启动浏览器
 func (b *Browser) Start() error {
-	opts := append(chromedp.DefaultExecAllocatorOptions[:],
-		chromedp.Flag("headless", b.config.Browser.Headless),
-		chromedp.Flag("disable-gpu", true),
-		chromedp.Flag("disable-dev-shm-usage", true),
-		chromedp.Flag("disable-extensions", true),
-		chromedp.Flag("no-sandbox", true),
-		chromedp.Flag("disable-logging", true),
-		chromedp.Flag("silent", true),
-		chromedp.Flag("disable-background-timer-throttling", true),
-		chromedp.Flag("disable-backgrounding-occluded-windows", true),
-		chromedp.Flag("disable-renderer-backgrounding", true),
-		// SSL证书相关配置 - 忽略证书错误
-		chromedp.Flag("ignore-certificate-errors", true),
-		chromedp.Flag("ignore-ssl-errors", true),
-		chromedp.Flag("ignore-certificate-errors-spki-list", true),
-		chromedp.Flag("ignore-certificate-errors-ssl-errors", true),
-		chromedp.Flag("allow-running-insecure-content", true),
-		chromedp.Flag("disable-web-security", true),
-		chromedp.Flag("allow-cross-origin-auth-prompt", true),
-		// 网络相关配置
-		chromedp.Flag("disable-features", "VizDisplayCompositor"),
-		chromedp.Flag("disable-ipc-flooding-protection", true),
-		chromedp.WindowSize(b.config.Browser.Width, b.config.Browser.Height),
-	)
+	// 计算启动参数（chromedp默认参数 + 工具默认参数 + browser.launch配置）
+	flags, removed := b.launchFlags()
+	opts := append(chromedp.DefaultExecAllocatorOptions[:], launchOptions(flags, removed)...)
+
+	if b.config.Browser.Launch.Strict {
+		b.logger.Info("🔒 严格模式：保留沙箱和Web安全策略，不忽略证书错误")
+	}
+	b.logger.Infof("Chrome启动参数: %s", formatLaunchFlags(flags))
+	if len(removed) > 0 {
+		b.logger.Infof("已移除的启动参数: %s", strings.Join(removed, ", "))
+	}
 
 	// 如果指定了Chrome路径，使用自定义路径
 	if b.config.Browser.ChromePath != "" {
 		opts = append(opts, chromedp.ExecPath(b.config.Browser.ChromePath))
-		b.logger.Info("使用指定的Chrome路径: %s", b.config.Browser.ChromePath)
+		b.logger.Infof("使用指定的Chrome路径: %s", b.config.Browser.ChromePath)
 	}
 
 	allocCtx, allocCancel := chromedp.NewExecAllocator(context.Background(), opts...)
@@ -74,14 +98,17 @@ func (b *Browser) Start() error {
 			strings.Contains(msg, "parse error") {
 			return // 忽略这些内部错误
 		}
-		// 只在debug模式下输出其他Chrome日志
-		if !b.config.Browser.Headless {
-			b.logger.Debug("Chrome: " + msg)
-		}
+		// 其他Chrome日志按debug级别输出（-debug模式下可见）
+		b.logger.Debug("Chrome: " + msg)
 	}
 
 	// 统一使用自定义日志函数
-	ctx, cancel := chromedp.NewContext(allocCtx, chromedp.WithLogf(customLogf))
+	ctx, cancel := chromedp.NewContext(allocCtx,
+		chromedp.WithLogf(customLogf),
+		chromedp.WithErrorf(func(format string, args ...interface{}) {
+			customLogf("ERROR: "+format, args...)
+		}),
+	)
 
 	if !b.config.Browser.Headless {
 		b.logger.Debug("🔍 调试模式：Chrome窗口可见，已屏蔽内部错误日志")
@@ -93,8 +120,24 @@ func (b *Browser) Start() error {
 		allocCancel()
 	}
 
+	// 注册页面事件监听
+	b.listen()
+
 	// 启动浏览器（不设置超时，因为这只是启动浏览器进程）
-	return chromedp.Run(b.ctx)
+	if err := chromedp.Run(b.ctx); err != nil {
+		return err
+	}
+
+	// 启用Security域以获取证书错误信息
+	if err := chromedp.Run(b.ctx, security.Enable()); err != nil {
+		b.logger.Debugf("启用Security域失败: %v", err)
+	}
+
+	// 启用静态资源拦截
+	if err := b.enableBlocking(); err != nil {
+		b.logger.Warnf("启用资源拦截失败: %v", err)
+	}
+	return nil
 }
 
 // Close 关闭浏览器
@@ -104,6 +147,49 @@ func (b *Browser) Close() {
 	}
 }
 
+// Restart 关闭并重新启动浏览器，重新应用当前的模拟配置档
+func (b *Browser) Restart() error {
+	b.mu.Lock()
+	cancels := b.tabCancels
+	b.mu.Unlock()
+
+	// 先释放新标签页的上下文，再关闭浏览器
+	for _, cancel := range cancels {
+		cancel()
+	}
+	b.Close()
+
+	b.mu.Lock()
+	b.navigation = nil
+	b.navIndex = make(map[network.RequestID]int)
+	b.dialogs = nil
+	b.console = nil
+	b.openedTabs = nil
+	b.tabContexts = make(map[target.ID]context.Context)
+	b.tabCancels = make(map[target.ID]context.CancelFunc)
+	b.tls = nil
+	b.certState = nil
+	b.document = nil
+	b.crashReason = ""
+	b.recording = false
+	b.frames = nil
+	b.mu.Unlock()
+
+	if err := b.Start(); err != nil {
+		return fmt.Errorf("重启浏览器失败: %v", err)
+	}
+
+	// 新的浏览器实例没有任何模拟设置，重新应用
+	name, profile := b.profileName, b.profile
+	b.profileName, b.profile = "", nil
+	if profile != nil {
+		if err := b.ApplyEmulation(name, profile); err != nil {
+			return fmt.Errorf("重新应用模拟配置档失败: %v", err)
+		}
+	}
+	return nil
+}
+
 // GetContext 获取浏览器上下文
 func (b *Browser) GetContext() context.Context {
 	return b.ctx
@@ -133,6 +219,9 @@ func (b *Browser) GetInputValue(selector string) (string, error) {
 func (b *Browser) NavigateTo(url string) error {
 	b.logger.Infof("导航到: %s", url)
 
+	// 控制台消息按页面收集
+	b.ResetConsole()
+
 	timeoutCtx, cancel := context.WithTimeout(b.ctx, time.Duration(b.config.Browser.Timeout)*time.Second)
 	defer cancel()
 
@@ -210,46 +299,43 @@ func (b *Browser) FillInput(selector, value string) error {
 		chromedp.Sleep(200*time.Millisecond),
 
 		// 第一步：彻底清空输入框
-		chromedp.Evaluate(fmt.Sprintf(`
+		CallFunction(`function(sel) {
 			try {
-				const el = document.querySelector('%s');
+				const el = document.querySelector(sel);
 				if (el) {
 					// 聚焦元素
 					el.focus();
-					
+
 					// 全选内容
 					el.select();
 					if (el.setSelectionRange) {
 						el.setSelectionRange(0, el.value.length);
 					}
-					
+
 					// 使用execCommand删除
 					document.execCommand('selectAll');
 					document.execCommand('delete');
-					
+
 					// 强制设置为空
 					el.value = '';
 					el.textContent = '';
 					if (el.innerHTML !== undefined) el.innerHTML = '';
-					
+
 					// 再次全选并删除以确保完全清空
 					el.select();
 					document.execCommand('delete');
 					el.value = '';
-					
-					console.log('Step 1 - Input cleared, value now: "' + el.value + '"');
 				}
 			} catch(e) { console.log('Step 1 clear failed:', e); }
-		`, escapeJSString(selector)), nil),
+		}`, nil, selector),
 
 		chromedp.Sleep(800*time.Millisecond), // 更长的等待时间确保清空完成
 
 		// 第二步：验证清空结果
-		chromedp.Evaluate(fmt.Sprintf(`
+		CallFunction(`function(sel) {
 			try {
-				const el = document.querySelector('%s');
+				const el = document.querySelector(sel);
 				if (el && el.value !== '') {
-					console.log('WARNING: Input not fully cleared, value: "' + el.value + '"');
 					// 如果还有内容，再次强制清空
 					el.value = '';
 					el.focus();
@@ -257,36 +343,33 @@ func (b *Browser) FillInput(selector, value string) error {
 					document.execCommand('delete');
 					el.value = '';
 				}
-				console.log('Step 2 - Final clear check, value: "' + el.value + '"');
 			} catch(e) { console.log('Step 2 verify failed:', e); }
-		`, escapeJSString(selector)), nil),
+		}`, nil, selector),
 
 		chromedp.Sleep(400*time.Millisecond),
 
-		// 第三步：设置新值
-		chromedp.Evaluate(fmt.Sprintf(`
+		// 第三步：设置新值（值以参数传递，不拼接到脚本中）
+		CallFunction(`function(sel, value) {
 			try {
-				const el = document.querySelector('%s');
+				const el = document.querySelector(sel);
 				if (el) {
 					// 确保元素处于聚焦状态
 					el.focus();
-					
+
 					// 最后一次确保清空
 					el.value = '';
-					
+
 					// 设置新值
-					el.value = '%s';
-					
+					el.value = value;
+
 					// 触发所有相关事件
 					el.dispatchEvent(new Event('input', { bubbles: true, cancelable: true }));
 					el.dispatchEvent(new Event('change', { bubbles: true, cancelable: true }));
 					el.dispatchEvent(new Event('keyup', { bubbles: true, cancelable: true }));
 					el.dispatchEvent(new Event('blur', { bubbles: true, cancelable: true }));
-					
-					console.log('Step 3 - Input filled with: "' + el.value + '"');
 				}
 			} catch(e) { console.log('Step 3 fill failed:', e); }
-		`, escapeJSString(selector), escapeJSString(value)), nil),
+		}`, nil, selector, value),
 
 		chromedp.Sleep(400*time.Millisecond), // 等待设置完成
 		chromedp.Sleep(500*time.Millisecond), // 确保输入完成
@@ -294,8 +377,8 @@ func (b *Browser) FillInput(selector, value string) error {
 
 	if err == nil {
 		// 验证输入是否成功
-		if err := b.verifyInput(selector, value); err != nil {
-			b.logger.Warnf("⚠️  输入验证失败，尝试重新输入: %v", err)
+		if verifyErr := b.verifyInput(selector, value); verifyErr != nil {
+			b.logger.Warnf("⚠️  输入验证失败，尝试重新输入: %v", verifyErr)
 			// 重试一次
 			err = b.retryFillInput(selector, value)
 		} else {
@@ -320,13 +403,13 @@ func (b *Browser) retryFillInput(selector, value string) error {
 		chromedp.Sleep(200*time.Millisecond),
 
 		// 使用更强力的清空和设置方法
-		chromedp.Evaluate(fmt.Sprintf(`
+		CallFunction(`function(sel) {
 			try {
-				const el = document.querySelector('%s');
+				const el = document.querySelector(sel);
 				if (el) {
 					// 更激进的清空方法
 					el.focus();
-					
+
 					// 连续多次全选删除
 					for (let i = 0; i < 3; i++) {
 						el.select();
@@ -337,36 +420,32 @@ func (b *Browser) retryFillInput(selector, value string) error {
 						document.execCommand('delete');
 						el.value = '';
 					}
-					
+
 					// 最后确保完全为空
 					el.value = '';
 					el.textContent = '';
 					if (el.innerHTML !== undefined) el.innerHTML = '';
-					
-					console.log('Retry clear completed, value: "' + el.value + '"');
 				}
 			} catch(e) { console.log('Retry clear failed:', e); }
-		`, escapeJSString(selector)), nil),
+		}`, nil, selector),
 
 		chromedp.Sleep(600*time.Millisecond), // 更长等待时间
 
 		// 设置新值
-		chromedp.Evaluate(fmt.Sprintf(`
+		CallFunction(`function(sel, value) {
 			try {
-				const el = document.querySelector('%s');
+				const el = document.querySelector(sel);
 				if (el) {
 					el.focus();
-					el.value = '%s';
-					
+					el.value = value;
+
 					// 触发事件
 					el.dispatchEvent(new Event('input', { bubbles: true, cancelable: true }));
 					el.dispatchEvent(new Event('change', { bubbles: true, cancelable: true }));
 					el.dispatchEvent(new Event('keyup', { bubbles: true, cancelable: true }));
-					
-					console.log('Retry fill completed, value: "' + el.value + '"');
 				}
 			} catch(e) { console.log('Retry fill failed:', e); }
-		`, escapeJSString(selector), escapeJSString(value)), nil),
+		}`, nil, selector, value),
 		chromedp.Sleep(500*time.Millisecond),
 	)
 
@@ -424,14 +503,14 @@ func (b *Browser) ClickElement(selector string) error {
 		// 如果普通点击失败，尝试JavaScript点击
 		b.logger.Debugf("普通点击失败，尝试JavaScript点击...")
 		err = chromedp.Run(timeoutCtx,
-			chromedp.Evaluate(fmt.Sprintf(`
+			CallFunction(`function(sel) {
 				try {
-					const el = document.querySelector('%s');
+					const el = document.querySelector(sel);
 					if (el) {
 						el.click();
 					}
 				} catch(e) { console.log('Click failed:', e); }
-			`, escapeJSString(selector)), nil),
+			}`, nil, selector),
 		)
 	}
 
@@ -476,14 +555,14 @@ func (b *Browser) ClickCheckbox(selector string) error {
 		// 如果普通点击失败，尝试用JavaScript点击
 		b.logger.Debugf("普通点击失败，尝试JavaScript点击")
 		err = chromedp.Run(timeoutCtx,
-			chromedp.Evaluate(fmt.Sprintf(`
+			CallFunction(`function(sel) {
 				try {
-					const checkbox = document.querySelector('%s');
+					const checkbox = document.querySelector(sel);
 					if (checkbox && !checkbox.checked) {
 						checkbox.click();
 					}
 				} catch(e) { console.log('Checkbox click failed:', e); }
-			`, escapeJSString(selector)), nil),
+			}`, nil, selector),
 		)
 	}
 
@@ -494,6 +573,36 @@ func (b *Browser) ClickCheckbox(selector string) error {
 	return err
 }
 
+// CollectErrorMessages 读取页面中可见的错误提示区域文本（如 role=alert、Element/Ant Design消息、layui弹层）
+func (b *Browser) CollectErrorMessages(selectors []string) ([]string, error) {
+	if len(selectors) == 0 {
+		return nil, nil
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(b.ctx, 3*time.Second)
+	defer cancel()
+
+	var messages []string
+	err := chromedp.Run(timeoutCtx,
+		CallFunction(`function(selectors) {
+			const messages = [];
+			for (const sel of selectors) {
+				let nodes = [];
+				try { nodes = document.querySelectorAll(sel); } catch (e) { continue; }
+				for (const el of nodes) {
+					const style = window.getComputedStyle(el);
+					if (style.display === 'none' || style.visibility === 'hidden') continue;
+					const text = (el.innerText || el.textContent || '').trim();
+					if (text && !messages.includes(text)) messages.push(text);
+				}
+			}
+			return messages;
+		}`, &messages, selectors),
+	)
+
+	return messages, err
+}
+
 // GetCurrentURL 获取当前URL
 func (b *Browser) GetCurrentURL() (string, error) {
 	var url string
@@ -514,13 +623,101 @@ func (b *Browser) Screenshot() ([]byte, error) {
 	return buf, err
 }
 
-// escapeJSString 转义JavaScript字符串中的特殊字符
-func escapeJSString(s string) string {
-	s = strings.ReplaceAll(s, "\\", "\\\\")
-	s = strings.ReplaceAll(s, "'", "\\'")
-	s = strings.ReplaceAll(s, "\"", "\\\"")
-	s = strings.ReplaceAll(s, "\n", "\\n")
-	s = strings.ReplaceAll(s, "\r", "\\r")
-	s = strings.ReplaceAll(s, "\t", "\\t")
-	return s
+// CaptureMHTML 获取当前页面的MHTML快照
+func (b *Browser) CaptureMHTML() (string, error) {
+	var snapshot string
+	timeoutCtx, cancel := context.WithTimeout(b.ctx, 30*time.Second)
+	defer cancel()
+
+	err := chromedp.Run(timeoutCtx, chromedp.ActionFunc(func(ctx context.Context) error {
+		var err error
+		snapshot, err = page.CaptureSnapshot().WithFormat(page.CaptureSnapshotFormatMhtml).Do(ctx)
+		return err
+	}))
+
+	return snapshot, err
+}
+
+// GetCookies 获取当前页面可见的Cookie
+func (b *Browser) GetCookies() ([]*network.Cookie, error) {
+	var cookies []*network.Cookie
+	timeoutCtx, cancel := context.WithTimeout(b.ctx, 10*time.Second)
+	defer cancel()
+
+	err := chromedp.Run(timeoutCtx, chromedp.ActionFunc(func(ctx context.Context) error {
+		var err error
+		cookies, err = network.GetCookies().Do(ctx)
+		return err
+	}))
+
+	return cookies, err
+}
+
+// FullScreenshot 整页截图，通过captureBeyondViewport截取视口之外的内容
+func (b *Browser) FullScreenshot(quality int) ([]byte, error) {
+	var buf []byte
+	timeoutCtx, cancel := context.WithTimeout(b.ctx, 20*time.Second)
+	defer cancel()
+
+	if quality <= 0 || quality > 100 {
+		quality = 100
+	}
+
+	err := chromedp.Run(timeoutCtx, chromedp.ActionFunc(func(ctx context.Context) error {
+		_, _, _, _, _, contentSize, err := page.GetLayoutMetrics().Do(ctx)
+		if err != nil {
+			return err
+		}
+
+		format := page.CaptureScreenshotFormatPng
+		if quality != 100 {
+			format = page.CaptureScreenshotFormatJpeg
+		}
+
+		params := page.CaptureScreenshot().
+			WithCaptureBeyondViewport(true).
+			WithFromSurface(true).
+			WithFormat(format)
+		if format == page.CaptureScreenshotFormatJpeg {
+			params = params.WithQuality(int64(quality))
+		}
+		if contentSize != nil && contentSize.Width > 0 && contentSize.Height > 0 {
+			params = params.WithClip(&page.Viewport{
+				X:      contentSize.X,
+				Y:      contentSize.Y,
+				Width:  contentSize.Width,
+				Height: contentSize.Height,
+				Scale:  1,
+			})
+		}
+
+		buf, err = params.Do(ctx)
+		return err
+	}))
+
+	return buf, err
+}
+
+// CallFunction 通过Runtime.callFunctionOn调用JavaScript函数，参数按值传递而不是拼接进脚本，
+// 任意字典内容（反引号、</script>、Unicode行分隔符等）都能原样传入页面
+func CallFunction(fn string, res interface{}, args ...interface{}) chromedp.Action {
+	return callGlobalFunction(fn, res, false, args...)
+}
+
+// callGlobalFunction 以页面全局对象为目标调用函数，awaitPromise为true时等待返回的Promise完成
+func callGlobalFunction(fn string, res interface{}, awaitPromise bool, args ...interface{}) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		// callFunctionOn需要一个目标对象，使用页面的全局对象
+		var global *runtime.RemoteObject
+		if err := chromedp.Evaluate("globalThis", &global).Do(ctx); err != nil {
+			return err
+		}
+		defer func() {
+			_ = runtime.ReleaseObject(global.ObjectID).Do(ctx)
+		}()
+
+		return chromedp.CallFunctionOn(fn, res, func(p *runtime.CallFunctionOnParams) *runtime.CallFunctionOnParams {
+			return p.WithObjectID(global.ObjectID).WithAwaitPromise(awaitPromise)
+		}, args...).Do(ctx)
+	})
 }
@@ -0,0 +1,129 @@
+package browser
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/chromedp/cdproto/runtime"
+)
+
+// maxConsoleMessages 每个页面/尝试最多保留的控制台消息数
+const maxConsoleMessages = 200
+
+// ConsoleMessage 页面控制台输出或未捕获的JavaScript异常
+type ConsoleMessage struct {
+	Level  string `json:"level"` // log/info/warning/error/debug，未捕获异常为exception
+	Text   string `json:"text"`
+	Source string `json:"source,omitempty"` // 脚本URL:行号
+}
+
+// String 格式化为单行文本
+func (m ConsoleMessage) String() string {
+	if m.Source != "" {
+		return fmt.Sprintf("[%s] %s (%s)", m.Level, m.Text, m.Source)
+	}
+	return fmt.Sprintf("[%s] %s", m.Level, m.Text)
+}
+
+// ConsoleMessages 获取当前页面/尝试期间收集到的控制台消息和异常
+func (b *Browser) ConsoleMessages() []ConsoleMessage {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	messages := make([]ConsoleMessage, len(b.console))
+	copy(messages, b.console)
+	return messages
+}
+
+// ConsoleErrors 获取错误级别的控制台消息和未捕获异常
+func (b *Browser) ConsoleErrors() []ConsoleMessage {
+	var errors []ConsoleMessage
+	for _, msg := range b.ConsoleMessages() {
+		if msg.Level == "error" || msg.Level == "exception" {
+			errors = append(errors, msg)
+		}
+	}
+	return errors
+}
+
+// ResetConsole 清空控制台消息记录
+func (b *Browser) ResetConsole() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.console = nil
+}
+
+// addConsoleMessage 记录一条控制台消息
+func (b *Browser) addConsoleMessage(msg ConsoleMessage) {
+	b.logger.Debugf("🖥️  Console %s", msg)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.console) < maxConsoleMessages {
+		b.console = append(b.console, msg)
+	}
+}
+
+// consoleFromAPICall 将console.*调用转换为ConsoleMessage
+func consoleFromAPICall(ev *runtime.EventConsoleAPICalled) ConsoleMessage {
+	args := make([]string, 0, len(ev.Args))
+	for _, arg := range ev.Args {
+		args = append(args, remoteObjectText(arg))
+	}
+
+	msg := ConsoleMessage{
+		Level: ev.Type.String(),
+		Text:  strings.Join(args, " "),
+	}
+	if ev.StackTrace != nil && len(ev.StackTrace.CallFrames) > 0 {
+		frame := ev.StackTrace.CallFrames[0]
+		msg.Source = fmt.Sprintf("%s:%d", frame.URL, frame.LineNumber+1)
+	}
+	return msg
+}
+
+// consoleFromException 将未捕获异常转换为ConsoleMessage
+func consoleFromException(ev *runtime.EventExceptionThrown) ConsoleMessage {
+	details := ev.ExceptionDetails
+	text := details.Text
+	if details.Exception != nil && details.Exception.Description != "" {
+		text = details.Exception.Description
+	}
+
+	msg := ConsoleMessage{
+		Level: "exception",
+		Text:  text,
+	}
+	if details.URL != "" {
+		msg.Source = fmt.Sprintf("%s:%d", details.URL, details.LineNumber+1)
+	}
+	return msg
+}
+
+// remoteObjectText 获取控制台参数的文本表示
+func remoteObjectText(obj *runtime.RemoteObject) string {
+	if obj == nil {
+		return ""
+	}
+	if len(obj.Value) > 0 {
+		value := string(obj.Value)
+		// 字符串值去掉JSON引号
+		if obj.Type == runtime.TypeString {
+			var s string
+			if err := json.Unmarshal(obj.Value, &s); err == nil {
+				return s
+			}
+		}
+		return value
+	}
+	if obj.UnserializableValue != "" {
+		return string(obj.UnserializableValue)
+	}
+	if obj.Description != "" {
+		return obj.Description
+	}
+	return obj.Type.String()
+}
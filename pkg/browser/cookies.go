@@ -0,0 +1,122 @@
+package browser
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/chromedp/cdproto/network"
+)
+
+// NetscapeCookies 将Cookie转换为Netscape cookies.txt格式（curl、wget、sqlmap等工具可直接读取）
+func NetscapeCookies(cookies []*network.Cookie) string {
+	var sb strings.Builder
+	sb.WriteString("# Netscape HTTP Cookie File\n")
+	sb.WriteString("# This file was generated by chrome_auto_login\n\n")
+
+	for _, c := range cookies {
+		domain := c.Domain
+		// curl使用#HttpOnly_前缀标记HttpOnly Cookie
+		if c.HTTPOnly {
+			domain = "#HttpOnly_" + domain
+		}
+
+		var expires int64
+		if !c.Session && c.Expires > 0 {
+			expires = int64(c.Expires)
+		}
+
+		path := c.Path
+		if path == "" {
+			path = "/"
+		}
+
+		fmt.Fprintf(&sb, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+			domain,
+			netscapeBool(strings.HasPrefix(c.Domain, ".")),
+			path,
+			netscapeBool(c.Secure),
+			expires,
+			c.Name,
+			c.Value,
+		)
+	}
+	return sb.String()
+}
+
+// CookieHeader 生成访问指定URL时浏览器会发送的Cookie请求头值
+func CookieHeader(cookies []*network.Cookie, rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	host := strings.ToLower(u.Hostname())
+	path := u.Path
+	if path == "" {
+		path = "/"
+	}
+
+	var matched []*network.Cookie
+	for _, c := range cookies {
+		if cookieMatches(c, host, path, u.Scheme == "https") {
+			matched = append(matched, c)
+		}
+	}
+
+	// 路径更长的Cookie排在前面（RFC 6265）
+	sort.SliceStable(matched, func(i, j int) bool {
+		return len(matched[i].Path) > len(matched[j].Path)
+	})
+
+	pairs := make([]string, 0, len(matched))
+	for _, c := range matched {
+		pairs = append(pairs, c.Name+"="+c.Value)
+	}
+	return strings.Join(pairs, "; ")
+}
+
+// CurlCommand 生成携带Cookie访问指定URL的curl命令
+func CurlCommand(cookies []*network.Cookie, rawURL string) string {
+	return fmt.Sprintf("curl -b %s %s", shellQuote(CookieHeader(cookies, rawURL)), shellQuote(rawURL))
+}
+
+// cookieMatches 判断Cookie是否会随该请求发送
+func cookieMatches(c *network.Cookie, host, path string, secure bool) bool {
+	if c.Secure && !secure {
+		return false
+	}
+
+	domain := strings.ToLower(c.Domain)
+	if strings.HasPrefix(domain, ".") {
+		domain = domain[1:]
+		if host != domain && !strings.HasSuffix(host, "."+domain) {
+			return false
+		}
+	} else if host != domain {
+		return false
+	}
+
+	cookiePath := c.Path
+	if cookiePath == "" || cookiePath == "/" {
+		return true
+	}
+	if path == cookiePath {
+		return true
+	}
+	return strings.HasPrefix(path, cookiePath) &&
+		(strings.HasSuffix(cookiePath, "/") || path[len(cookiePath)] == '/')
+}
+
+// netscapeBool Netscape格式中的布尔值
+func netscapeBool(v bool) string {
+	if v {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+// shellQuote 使用单引号转义shell参数
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,79 @@
+package browser
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+// DocumentResponse 导航过程中捕获的主文档响应
+type DocumentResponse struct {
+	URL       string            `json:"url"`
+	Status    int64             `json:"status"`
+	MimeType  string            `json:"mime_type"`
+	Headers   map[string]string `json:"headers"` // 响应头，键为小写
+	requestID network.RequestID
+}
+
+// newDocumentResponse 从ResponseReceived事件记录主文档响应
+func newDocumentResponse(requestID network.RequestID, resp *network.Response) *DocumentResponse {
+	headers := make(map[string]string, len(resp.Headers))
+	for key, value := range resp.Headers {
+		headers[strings.ToLower(key)] = fmt.Sprintf("%v", value)
+	}
+	return &DocumentResponse{
+		URL:       resp.URL,
+		Status:    resp.Status,
+		MimeType:  resp.MimeType,
+		Headers:   headers,
+		requestID: requestID,
+	}
+}
+
+// Document 获取最近一次导航的主文档响应，尚未导航时返回nil
+func (b *Browser) Document() *DocumentResponse {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.document == nil {
+		return nil
+	}
+	doc := *b.document
+	return &doc
+}
+
+// DocumentBody 通过Network.getResponseBody获取主文档响应体。
+// 返回的raw为true时是服务器发送的原始字节；为false时Chrome已按页面编码解码为UTF-8文本，不能再次转换
+func (b *Browser) DocumentBody() (body []byte, raw bool, err error) {
+	doc := b.Document()
+	if doc == nil {
+		return nil, false, fmt.Errorf("未捕获到主文档响应")
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(b.ctx, 10*time.Second)
+	defer cancel()
+
+	// 直接执行命令以保留base64Encoded标志，GetResponseBody().Do会丢弃它
+	var res network.GetResponseBodyReturns
+	err = chromedp.Run(timeoutCtx, chromedp.ActionFunc(func(ctx context.Context) error {
+		return cdp.Execute(ctx, network.CommandGetResponseBody, network.GetResponseBody(doc.requestID), &res)
+	}))
+	if err != nil {
+		return nil, false, fmt.Errorf("获取文档响应体失败: %v", err)
+	}
+
+	if !res.Base64encoded {
+		return []byte(res.Body), false, nil
+	}
+	body, err = base64.StdEncoding.DecodeString(res.Body)
+	if err != nil {
+		return nil, false, fmt.Errorf("解码文档响应体失败: %v", err)
+	}
+	return body, true, nil
+}
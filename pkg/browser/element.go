@@ -0,0 +1,167 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/dom"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+)
+
+// ElementRef 检测阶段选中的具体节点
+type ElementRef struct {
+	Selector      string            `json:"selector"`                  // 只匹配该节点的唯一选择器
+	BackendNodeID cdp.BackendNodeID `json:"backend_node_id,omitempty"` // 节点的后端ID，页面重新加载后失效
+}
+
+// uniqueSelectorScript 在元素上调用，依次尝试唯一id、表单内的name和带nth-of-type的CSS路径
+const uniqueSelectorScript = `function() {
+	const el = this;
+	const esc = (s) => (window.CSS && CSS.escape) ? CSS.escape(s) : String(s).replace(/([^\w-])/g, '\\$1');
+	const unique = (sel) => {
+		try {
+			const all = document.querySelectorAll(sel);
+			return all.length === 1 && all[0] === el;
+		} catch (e) {
+			return false;
+		}
+	};
+	const cssPath = (node) => {
+		const parts = [];
+		for (let cur = node; cur && cur.nodeType === 1; cur = cur.parentElement) {
+			if (cur.id && document.querySelectorAll('#' + esc(cur.id)).length === 1) {
+				parts.unshift('#' + esc(cur.id));
+				return parts.join(' > ');
+			}
+			if (cur === document.documentElement) {
+				parts.unshift('html');
+				break;
+			}
+			let index = 1;
+			for (let sib = cur.previousElementSibling; sib; sib = sib.previousElementSibling) {
+				if (sib.tagName === cur.tagName) index++;
+			}
+			parts.unshift(cur.tagName.toLowerCase() + ':nth-of-type(' + index + ')');
+		}
+		return parts.join(' > ');
+	};
+
+	if (el.id && unique('#' + esc(el.id))) {
+		return '#' + esc(el.id);
+	}
+
+	const name = el.getAttribute('name');
+	if (name) {
+		const byName = el.tagName.toLowerCase() + '[name="' + name.replace(/["\\]/g, '\\$&') + '"]';
+		if (unique(byName)) {
+			return byName;
+		}
+		const form = el.form || el.closest('form');
+		if (form) {
+			const inForm = cssPath(form) + ' ' + byName;
+			if (unique(inForm)) {
+				return inForm;
+			}
+		}
+	}
+
+	return cssPath(el);
+}`
+
+// ResolveElement 把选择器命中的第一个节点解析为唯一选择器和后端节点ID
+func (b *Browser) ResolveElement(selector string) (*ElementRef, error) {
+	timeoutCtx, cancel := context.WithTimeout(b.ctx, 5*time.Second)
+	defer cancel()
+
+	var ref *ElementRef
+	err := chromedp.Run(timeoutCtx, chromedp.ActionFunc(func(ctx context.Context) error {
+		var nodes []*cdp.Node
+		if err := chromedp.Nodes(selector, &nodes, chromedp.ByQuery, chromedp.AtLeast(0)).Do(ctx); err != nil {
+			return err
+		}
+		if len(nodes) == 0 {
+			return fmt.Errorf("未找到元素: %s", selector)
+		}
+
+		unique, err := uniqueSelector(ctx, nodes[0].BackendNodeID)
+		if err != nil {
+			return err
+		}
+		ref = &ElementRef{Selector: unique, BackendNodeID: nodes[0].BackendNodeID}
+		return nil
+	}))
+	if err != nil {
+		return nil, fmt.Errorf("解析元素失败: %v", err)
+	}
+
+	b.logger.Debugf("元素 %s 解析为 %s (backend %d)", selector, ref.Selector, ref.BackendNodeID)
+	return ref, nil
+}
+
+// Locate 返回节点当前的唯一选择器：选择器仍指向原节点时直接使用，
+// 节点被移动或兄弟节点变化时按后端节点ID重新计算，节点已不存在时退回原选择器
+func (b *Browser) Locate(ref *ElementRef) string {
+	if ref == nil {
+		return ""
+	}
+	if ref.BackendNodeID == 0 {
+		return ref.Selector
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(b.ctx, 5*time.Second)
+	defer cancel()
+
+	selector := ref.Selector
+	err := chromedp.Run(timeoutCtx, chromedp.ActionFunc(func(ctx context.Context) error {
+		var nodes []*cdp.Node
+		if err := chromedp.Nodes(ref.Selector, &nodes, chromedp.ByQuery, chromedp.AtLeast(0)).Do(ctx); err != nil {
+			return err
+		}
+		if len(nodes) > 0 && nodes[0].BackendNodeID == ref.BackendNodeID {
+			return nil
+		}
+
+		unique, err := uniqueSelector(ctx, ref.BackendNodeID)
+		if err != nil {
+			return err
+		}
+		selector = unique
+		return nil
+	}))
+	if err != nil {
+		b.logger.Debugf("按后端节点ID定位失败，使用原选择器 %s: %v", ref.Selector, err)
+		return ref.Selector
+	}
+
+	if selector != ref.Selector {
+		b.logger.Debugf("元素选择器已更新: %s -> %s", ref.Selector, selector)
+		ref.Selector = selector
+	}
+	return selector
+}
+
+// uniqueSelector 为后端节点计算唯一选择器
+func uniqueSelector(ctx context.Context, id cdp.BackendNodeID) (string, error) {
+	obj, err := dom.ResolveNode().WithBackendNodeID(id).Do(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = runtime.ReleaseObject(obj.ObjectID).Do(ctx)
+	}()
+
+	var selector string
+	err = chromedp.CallFunctionOn(uniqueSelectorScript, &selector, func(p *runtime.CallFunctionOnParams) *runtime.CallFunctionOnParams {
+		return p.WithObjectID(obj.ObjectID)
+	}).Do(ctx)
+	if err != nil {
+		return "", err
+	}
+	if selector == "" {
+		return "", fmt.Errorf("无法为节点 %d 生成选择器", id)
+	}
+	return selector, nil
+}
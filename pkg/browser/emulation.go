@@ -0,0 +1,154 @@
+package browser
+
+import (
+	"context"
+	"time"
+
+	cdpbrowser "github.com/chromedp/cdproto/browser"
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/emulation"
+	"github.com/chromedp/chromedp"
+
+	"github.com/cyberspacesec/chrome_auto_login/pkg/config"
+)
+
+// ApplyEmulation 通过CDP Emulation域应用模拟配置档，profile为nil时清除所有模拟
+func (b *Browser) ApplyEmulation(name string, profile *config.EmulationProfile) error {
+	if profile == nil {
+		name = ""
+	}
+	if profile == nil && b.profile == nil {
+		return nil
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(b.ctx, 10*time.Second)
+	defer cancel()
+
+	var actions []chromedp.Action
+	if b.profile != nil {
+		actions = append(actions, b.clearEmulation())
+	}
+	if profile != nil {
+		actions = append(actions, b.emulate(profile))
+	}
+	if err := chromedp.Run(timeoutCtx, actions...); err != nil {
+		// 部分设置可能已经生效，无论之前是否应用过配置档都恢复默认设置，调用方可以继续使用浏览器
+		clearCtx, clearCancel := context.WithTimeout(b.ctx, 5*time.Second)
+		defer clearCancel()
+		if clearErr := chromedp.Run(clearCtx, b.clearEmulation()); clearErr != nil {
+			b.logger.Debugf("恢复默认浏览器设置失败: %v", clearErr)
+		}
+		b.profileName, b.profile = "", nil
+		return err
+	}
+
+	b.profileName = name
+	b.profile = profile
+	if profile != nil {
+		b.logger.Infof("📱 已应用模拟配置档: %s", name)
+	}
+	return nil
+}
+
+// EmulationProfile 获取当前使用的模拟配置档名称
+func (b *Browser) EmulationProfile() string {
+	return b.profileName
+}
+
+// emulate 应用模拟配置档中设置的各项参数
+func (b *Browser) emulate(profile *config.EmulationProfile) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		// 视口与设备像素比
+		if profile.Width > 0 && profile.Height > 0 {
+			scale := profile.DeviceScaleFactor
+			if scale <= 0 {
+				scale = 1
+			}
+			if err := emulation.SetDeviceMetricsOverride(int64(profile.Width), int64(profile.Height), scale, profile.Mobile).
+				WithScreenWidth(int64(profile.Width)).
+				WithScreenHeight(int64(profile.Height)).
+				Do(ctx); err != nil {
+				return err
+			}
+		}
+
+		// 触摸
+		if profile.Touch {
+			if err := emulation.SetTouchEmulationEnabled(true).WithMaxTouchPoints(5).Do(ctx); err != nil {
+				return err
+			}
+		}
+
+		// User-Agent与Accept-Language
+		if profile.UserAgent != "" || profile.AcceptLanguage != "" || profile.Platform != "" {
+			userAgent := profile.UserAgent
+			if userAgent == "" {
+				_, _, _, ua, _, err := cdpbrowser.GetVersion().Do(ctx)
+				if err != nil {
+					return err
+				}
+				userAgent = ua
+			}
+			params := emulation.SetUserAgentOverride(userAgent)
+			if profile.AcceptLanguage != "" {
+				params = params.WithAcceptLanguage(profile.AcceptLanguage)
+			}
+			if profile.Platform != "" {
+				params = params.WithPlatform(profile.Platform)
+			}
+			if err := params.Do(ctx); err != nil {
+				return err
+			}
+		}
+
+		// 区域与时区
+		if profile.Locale != "" {
+			if err := emulation.SetLocaleOverride().WithLocale(profile.Locale).Do(ctx); err != nil {
+				return err
+			}
+		}
+		if profile.Timezone != "" {
+			if err := emulation.SetTimezoneOverride(profile.Timezone).Do(ctx); err != nil {
+				return err
+			}
+		}
+
+		// 地理位置（需要授予定位权限）
+		if geo := profile.Geolocation; geo != nil {
+			browserCtx := cdp.WithExecutor(ctx, chromedp.FromContext(ctx).Browser)
+			if err := cdpbrowser.GrantPermissions([]cdpbrowser.PermissionType{cdpbrowser.PermissionTypeGeolocation}).Do(browserCtx); err != nil {
+				b.logger.Debugf("授予定位权限失败: %v", err)
+			}
+			accuracy := geo.Accuracy
+			if accuracy <= 0 {
+				accuracy = 100
+			}
+			if err := emulation.SetGeolocationOverride().
+				WithLatitude(geo.Latitude).
+				WithLongitude(geo.Longitude).
+				WithAccuracy(accuracy).
+				Do(ctx); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// clearEmulation 清除所有模拟设置，恢复浏览器默认值
+func (b *Browser) clearEmulation() chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		_ = emulation.ClearDeviceMetricsOverride().Do(ctx)
+		_ = emulation.SetTouchEmulationEnabled(false).Do(ctx)
+		_ = emulation.SetLocaleOverride().Do(ctx)
+		_ = emulation.SetTimezoneOverride("").Do(ctx)
+		_ = emulation.ClearGeolocationOverride().Do(ctx)
+
+		// User-Agent无法直接清除，恢复为浏览器默认值
+		if _, _, _, ua, _, err := cdpbrowser.GetVersion().Do(ctx); err == nil {
+			_ = emulation.SetUserAgentOverride(ua).WithAcceptLanguage("").Do(ctx)
+		}
+		return nil
+	})
+}
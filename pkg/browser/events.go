@@ -0,0 +1,168 @@
+package browser
+
+import (
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/inspector"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/cdproto/security"
+	"github.com/chromedp/cdproto/target"
+	"github.com/chromedp/chromedp"
+)
+
+// NavigationHop 主框架的一次文档导航（含重定向）
+type NavigationHop struct {
+	URL    string `json:"url"`
+	Status int64  `json:"status,omitempty"`
+}
+
+// listen 注册页面事件监听，记录导航链等页面状态
+func (b *Browser) listen() {
+	ctx := b.ctx
+	chromedp.ListenTarget(ctx, func(ev interface{}) {
+		switch ev := ev.(type) {
+		case *network.EventRequestWillBeSent:
+			if ev.Type != network.ResourceTypeDocument || !b.isMainFrame(ev.FrameID) {
+				return
+			}
+			b.mu.Lock()
+			if ev.RedirectResponse != nil {
+				if idx, ok := b.navIndex[ev.RequestID]; ok {
+					b.navigation[idx].Status = ev.RedirectResponse.Status
+				}
+			}
+			b.navigation = append(b.navigation, NavigationHop{URL: ev.Request.URL})
+			b.navIndex[ev.RequestID] = len(b.navigation) - 1
+			b.mu.Unlock()
+
+		case *page.EventJavascriptDialogOpening:
+			// 自动处理alert/confirm/prompt，避免对话框阻塞页面
+			b.logger.Debugf("💬 捕获到JavaScript对话框(%s): %s", ev.Type, ev.Message)
+			b.mu.Lock()
+			b.dialogs = append(b.dialogs, ev.Message)
+			b.mu.Unlock()
+			go func() {
+				if err := chromedp.Run(b.ctx, page.HandleJavaScriptDialog(true)); err != nil {
+					b.logger.Debugf("处理JavaScript对话框失败: %v", err)
+				}
+			}()
+
+		case *target.EventTargetCreated:
+			// 记录由当前页面打开的新窗口/新标签页（window.open、target=_blank）
+			info := ev.TargetInfo
+			if info.Type != "page" || !b.isOwnTarget(info.OpenerID) {
+				return
+			}
+			b.logger.Debugf("🪟 检测到新打开的标签页: %s", info.URL)
+			b.mu.Lock()
+			b.openedTabs = append(b.openedTabs, info.TargetID)
+			b.mu.Unlock()
+
+		case *network.EventResponseReceived:
+			if ev.Type != network.ResourceTypeDocument || !b.isMainFrame(ev.FrameID) {
+				return
+			}
+			b.mu.Lock()
+			if idx, ok := b.navIndex[ev.RequestID]; ok {
+				b.navigation[idx].Status = ev.Response.Status
+			}
+			// 记录主文档的TLS信息，HTTP页面会清空之前的记录
+			b.tls = newTLSInfo(ev.Response)
+			b.document = newDocumentResponse(ev.RequestID, ev.Response)
+			b.mu.Unlock()
+
+		case *page.EventScreencastFrame:
+			b.handleScreencastFrame(ev)
+
+		case *fetch.EventRequestPaused:
+			b.handleRequestPaused(ev)
+
+		case *runtime.EventConsoleAPICalled:
+			b.addConsoleMessage(consoleFromAPICall(ev))
+
+		case *runtime.EventExceptionThrown:
+			if ev.ExceptionDetails != nil {
+				b.addConsoleMessage(consoleFromException(ev))
+			}
+
+		case *inspector.EventTargetCrashed:
+			b.logger.Warn("💥 标签页已崩溃")
+			b.markCrashed("标签页崩溃")
+
+		case *inspector.EventDetached:
+			// 主动关闭（Close/Restart）时上下文已结束，不视为崩溃
+			if ctx.Err() != nil {
+				return
+			}
+			b.logger.Warnf("💥 与标签页的调试连接已断开: %s", ev.Reason)
+			b.markCrashed("调试连接断开: " + ev.Reason.String())
+
+		case *security.EventVisibleSecurityStateChanged:
+			// Chrome对当前页面证书的判断（忽略证书错误时仍会报告错误码）
+			state := ev.VisibleSecurityState
+			b.mu.Lock()
+			if state != nil {
+				b.certState = state.CertificateSecurityState
+			} else {
+				b.certState = nil
+			}
+			b.mu.Unlock()
+		}
+	})
+}
+
+// isMainFrame 判断是否为当前标签页的主框架（主框架ID与目标ID一致）
+func (b *Browser) isMainFrame(frameID cdp.FrameID) bool {
+	return b.isOwnTarget(target.ID(frameID))
+}
+
+// isOwnTarget 判断是否为当前标签页
+func (b *Browser) isOwnTarget(id target.ID) bool {
+	c := chromedp.FromContext(b.ctx)
+	if c == nil || c.Target == nil || id == "" {
+		return false
+	}
+	return id == c.Target.TargetID
+}
+
+// BeginAttempt 开始一次新的登录尝试，清空上一次尝试记录的页面状态
+func (b *Browser) BeginAttempt() {
+	b.ResetNavigationLog()
+	b.CloseOpenedTabs()
+
+	b.mu.Lock()
+	b.dialogs = nil
+	b.console = nil
+	b.mu.Unlock()
+}
+
+// DialogMessages 获取本次尝试中出现的JavaScript对话框文本
+func (b *Browser) DialogMessages() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	messages := make([]string, len(b.dialogs))
+	copy(messages, b.dialogs)
+	return messages
+}
+
+// ResetNavigationLog 清空导航记录
+func (b *Browser) ResetNavigationLog() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.navigation = nil
+	b.navIndex = make(map[network.RequestID]int)
+}
+
+// NavigationLog 获取自上次清空以来的导航链（含重定向）
+func (b *Browser) NavigationLog() []NavigationHop {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	hops := make([]NavigationHop, len(b.navigation))
+	copy(hops, b.navigation)
+	return hops
+}
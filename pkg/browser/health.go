@@ -0,0 +1,47 @@
+package browser
+
+import (
+	"context"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// markCrashed 记录标签页或浏览器已失效
+func (b *Browser) markCrashed(reason string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.crashReason == "" {
+		b.crashReason = reason
+	}
+}
+
+// CrashReason 获取浏览器失效原因，正常时返回空字符串
+func (b *Browser) CrashReason() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.crashReason
+}
+
+// Alive 检查浏览器是否仍可用（未崩溃、上下文未结束且页面能及时响应）
+func (b *Browser) Alive() bool {
+	if b.ctx == nil || b.ctx.Err() != nil {
+		return false
+	}
+	if b.CrashReason() != "" {
+		return false
+	}
+
+	// 执行一个简单脚本，检测标签页是否卡死
+	probeCtx, cancel := context.WithTimeout(b.ctx, 5*time.Second)
+	defer cancel()
+
+	var result int
+	if err := chromedp.Run(probeCtx, chromedp.Evaluate(`1`, &result)); err != nil {
+		b.markCrashed("页面无响应: " + err.Error())
+		return false
+	}
+	return true
+}
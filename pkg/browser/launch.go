@@ -0,0 +1,156 @@
+package browser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chromedp/chromedp"
+)
+
+// launchFlag Chrome启动参数
+type launchFlag struct {
+	name  string
+	value interface{}
+}
+
+// insecureFlags 严格模式下移除的参数（关闭沙箱、Web安全策略和证书校验的参数）
+var insecureFlags = []string{
+	"no-sandbox",
+	"disable-web-security",
+	"ignore-certificate-errors",
+	"ignore-ssl-errors",
+	"ignore-certificate-errors-spki-list",
+	"ignore-certificate-errors-ssl-errors",
+	"allow-running-insecure-content",
+	"allow-cross-origin-auth-prompt",
+}
+
+// defaultLaunchFlags 工具默认使用的启动参数（在chromedp默认参数基础上追加）
+func (b *Browser) defaultLaunchFlags() []launchFlag {
+	return []launchFlag{
+		{"headless", b.config.Browser.Headless},
+		{"disable-gpu", true},
+		{"disable-dev-shm-usage", true},
+		{"disable-extensions", true},
+		{"no-sandbox", true},
+		{"disable-logging", true},
+		{"silent", true},
+		{"disable-background-timer-throttling", true},
+		{"disable-backgrounding-occluded-windows", true},
+		{"disable-renderer-backgrounding", true},
+		// SSL证书相关配置 - 忽略证书错误
+		{"ignore-certificate-errors", true},
+		{"ignore-ssl-errors", true},
+		{"ignore-certificate-errors-spki-list", true},
+		{"ignore-certificate-errors-ssl-errors", true},
+		{"allow-running-insecure-content", true},
+		{"disable-web-security", true},
+		{"allow-cross-origin-auth-prompt", true},
+		// 网络相关配置
+		{"disable-features", "VizDisplayCompositor"},
+		{"disable-ipc-flooding-protection", true},
+		{"window-size", fmt.Sprintf("%d,%d", b.config.Browser.Width, b.config.Browser.Height)},
+	}
+}
+
+// launchFlags 根据browser.launch配置计算最终的启动参数，返回生效参数和被移除的参数名
+func (b *Browser) launchFlags() ([]launchFlag, []string) {
+	launch := b.config.Browser.Launch
+
+	removed := make(map[string]bool)
+	var removedNames []string
+	remove := func(name string) {
+		name = strings.TrimLeft(strings.TrimSpace(name), "-")
+		if name != "" && !removed[name] {
+			removed[name] = true
+			removedNames = append(removedNames, name)
+		}
+	}
+	if launch.Strict {
+		for _, name := range insecureFlags {
+			remove(name)
+		}
+	}
+	for _, name := range launch.RemoveFlags {
+		remove(name)
+	}
+
+	var flags []launchFlag
+	for _, flag := range b.defaultLaunchFlags() {
+		if !removed[flag.name] {
+			flags = append(flags, flag)
+		}
+	}
+
+	// 追加自定义参数，同名参数覆盖默认值
+	for _, raw := range launch.AddFlags {
+		flag, ok := parseLaunchFlag(raw)
+		if !ok {
+			continue
+		}
+		replaced := false
+		for i := range flags {
+			if flags[i].name == flag.name {
+				flags[i] = flag
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			flags = append(flags, flag)
+		}
+	}
+
+	// 主机名解析覆盖
+	if len(b.resolve) > 0 {
+		flags = append(flags, launchFlag{"host-resolver-rules", hostResolverRules(b.resolve)})
+	}
+
+	// 用户数据目录：空或temp使用chromedp创建的临时目录，其他值为持久化目录
+	if dir := launch.UserDataDir; dir != "" && dir != "temp" {
+		flags = append(flags, launchFlag{"user-data-dir", dir})
+	}
+
+	return flags, removedNames
+}
+
+// parseLaunchFlag 解析 "--name=value" 或 "name" 形式的启动参数
+func parseLaunchFlag(raw string) (launchFlag, bool) {
+	raw = strings.TrimLeft(strings.TrimSpace(raw), "-")
+	if raw == "" {
+		return launchFlag{}, false
+	}
+	if name, value, ok := strings.Cut(raw, "="); ok {
+		return launchFlag{name, value}, true
+	}
+	return launchFlag{raw, true}, true
+}
+
+// launchOptions 将启动参数转换为chromedp分配器选项
+func launchOptions(flags []launchFlag, removed []string) []chromedp.ExecAllocatorOption {
+	opts := make([]chromedp.ExecAllocatorOption, 0, len(flags)+len(removed))
+	// 被移除的参数设置为false，chromedp默认参数中的同名参数也会被去掉
+	for _, name := range removed {
+		opts = append(opts, chromedp.Flag(name, false))
+	}
+	for _, flag := range flags {
+		opts = append(opts, chromedp.Flag(flag.name, flag.value))
+	}
+	return opts
+}
+
+// formatLaunchFlags 格式化启动参数用于日志输出
+func formatLaunchFlags(flags []launchFlag) string {
+	parts := make([]string, 0, len(flags))
+	for _, flag := range flags {
+		switch v := flag.value.(type) {
+		case bool:
+			if v {
+				parts = append(parts, "--"+flag.name)
+			}
+		default:
+			parts = append(parts, fmt.Sprintf("--%s=%v", flag.name, v))
+		}
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,109 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// 渲染等待结束的原因
+const (
+	RenderQuiet   = "quiet"   // 有内容的页面在静默期内没有变化
+	RenderInput   = "input"   // 页面中出现了输入框
+	RenderTimeout = "timeout" // 达到最长等待时间
+)
+
+// RenderState 客户端渲染等待结果
+type RenderState struct {
+	Reason    string `json:"reason"`
+	ElapsedMs int64  `json:"elapsed_ms"`
+	Mutations int    `json:"mutations"` // 等待期间观察到的DOM变化次数
+	HasInput  bool   `json:"has_input"`
+}
+
+// renderWaitScript 用MutationObserver等待DOM稳定：出现输入框、有内容的页面静默期内无变化或超时即结束。
+// 只有加载动画的空白页面在等待接口返回时DOM也不会变化，因此不以静默期作为结束条件
+const renderWaitScript = `function(quietMs, maxMs) {
+	return new Promise((resolve) => {
+		const start = performance.now();
+		let last = start, mutations = 0;
+		const hasInput = () => !!document.querySelector('input:not([type="hidden"]), textarea');
+		const hasContent = () => !!document.body && (document.body.innerText || '').trim().length >= 50;
+
+		const observer = new MutationObserver((records) => {
+			mutations += records.length;
+			last = performance.now();
+		});
+		observer.observe(document.documentElement, { childList: true, subtree: true, attributes: true, characterData: true });
+
+		const timer = setInterval(() => {
+			const now = performance.now();
+			let reason = '';
+			if (document.readyState === 'complete' && now - last >= quietMs && (hasInput() || hasContent())) {
+				reason = 'quiet';
+			} else if (hasInput() && now - last >= Math.min(quietMs, 200)) {
+				reason = 'input';
+			} else if (now - start >= maxMs) {
+				reason = 'timeout';
+			}
+			if (reason) {
+				observer.disconnect();
+				clearInterval(timer);
+				resolve({ reason: reason, elapsed_ms: Math.round(now - start), mutations: mutations, has_input: hasInput() });
+			}
+		}, 50);
+	});
+}`
+
+// hashChangeScript 等待地址中的哈希路由变化，超时返回空字符串
+const hashChangeScript = `function(maxMs) {
+	return new Promise((resolve) => {
+		const origin = location.href;
+		const start = performance.now();
+		const timer = setInterval(() => {
+			if (location.href !== origin) {
+				clearInterval(timer);
+				resolve(location.href);
+			} else if (performance.now() - start >= maxMs) {
+				clearInterval(timer);
+				resolve('');
+			}
+		}, 100);
+	});
+}`
+
+// WaitForRender 等待客户端渲染完成：DOM连续quiet时间无变化或出现输入框，最长等待maxWait
+func (b *Browser) WaitForRender(quiet, maxWait time.Duration) (*RenderState, error) {
+	timeoutCtx, cancel := context.WithTimeout(b.ctx, maxWait+5*time.Second)
+	defer cancel()
+
+	var state RenderState
+	if err := chromedp.Run(timeoutCtx, callGlobalFunction(renderWaitScript, &state, true, quiet.Milliseconds(), maxWait.Milliseconds())); err != nil {
+		return nil, fmt.Errorf("等待页面渲染失败: %v", err)
+	}
+
+	b.logger.Debugf("页面渲染等待结束: %s，用时 %dms，DOM变化 %d 次", state.Reason, state.ElapsedMs, state.Mutations)
+	return &state, nil
+}
+
+// WaitForHashChange 等待页面地址变化（哈希路由跳转），返回新地址，超时返回空字符串
+func (b *Browser) WaitForHashChange(maxWait time.Duration) (string, error) {
+	timeoutCtx, cancel := context.WithTimeout(b.ctx, maxWait+5*time.Second)
+	defer cancel()
+
+	var url string
+	if err := chromedp.Run(timeoutCtx, callGlobalFunction(hashChangeScript, &url, true, maxWait.Milliseconds())); err != nil {
+		return "", fmt.Errorf("等待路由变化失败: %v", err)
+	}
+	return url, nil
+}
+
+// SetHash 切换哈希路由，不重新加载页面
+func (b *Browser) SetHash(hash string) error {
+	timeoutCtx, cancel := context.WithTimeout(b.ctx, 5*time.Second)
+	defer cancel()
+
+	return chromedp.Run(timeoutCtx, CallFunction(`function(hash) { location.hash = hash; }`, nil, hash))
+}
@@ -0,0 +1,135 @@
+package browser
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// FormState 登录表单的当前状态
+type FormState struct {
+	Fingerprint   string `json:"fingerprint"`    // 表单结构指纹（控件类型、名称、ID）
+	FieldsPresent bool   `json:"fields_present"` // 用户名和密码输入框可见
+	SubmitReady   bool   `json:"submit_ready"`   // 提交按钮可见且未被禁用
+}
+
+// Usable 表单是否可用于下一次尝试
+func (s *FormState) Usable() bool {
+	return s != nil && s.FieldsPresent && s.SubmitReady
+}
+
+// InspectForm 检查登录表单状态，用于判断页面是否需要重新加载
+func (b *Browser) InspectForm(usernameSelector, passwordSelector, submitSelector string) (*FormState, error) {
+	timeoutCtx, cancel := context.WithTimeout(b.ctx, 5*time.Second)
+	defer cancel()
+
+	var res struct {
+		Structure     string `json:"structure"`
+		FieldsPresent bool   `json:"fieldsPresent"`
+		SubmitReady   bool   `json:"submitReady"`
+	}
+	err := chromedp.Run(timeoutCtx,
+		CallFunction(`function(userSel, passSel, submitSel) {
+			const q = (sel) => {
+				try { return sel ? document.querySelector(sel) : null; } catch (e) { return null; }
+			};
+			const visible = (el) => !!el && !!(el.offsetWidth || el.offsetHeight || el.getClientRects().length);
+
+			const user = q(userSel), pass = q(passSel), submit = q(submitSel);
+			const root = (pass && (pass.form || pass.closest('form'))) || document.body;
+
+			// 只记录控件结构，不记录值（CSRF令牌等值每次加载都会变化）
+			const parts = [];
+			if (root) {
+				root.querySelectorAll('input, select, textarea, button').forEach((el) => {
+					parts.push([el.tagName, el.type || '', el.name || '', el.id || ''].join(':'));
+				});
+			}
+
+			return {
+				structure: parts.join('|'),
+				fieldsPresent: visible(user) && visible(pass),
+				submitReady: visible(submit) && !submit.disabled && submit.getAttribute('aria-disabled') !== 'true',
+			};
+		}`, &res, usernameSelector, passwordSelector, submitSelector),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("检查登录表单失败: %v", err)
+	}
+
+	sum := sha256.Sum256([]byte(res.Structure))
+	return &FormState{
+		Fingerprint:   hex.EncodeToString(sum[:8]),
+		FieldsPresent: res.FieldsPresent,
+		SubmitReady:   res.SubmitReady,
+	}, nil
+}
+
+// ResetForm 原地重置登录表单：清空输入框并关闭错误提示
+func (b *Browser) ResetForm(fieldSelectors, errorSelectors []string) error {
+	b.logger.Debug("🧹 原地重置登录表单")
+
+	// nil切片会被序列化为null
+	if fieldSelectors == nil {
+		fieldSelectors = []string{}
+	}
+	if errorSelectors == nil {
+		errorSelectors = []string{}
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(b.ctx, 5*time.Second)
+	defer cancel()
+
+	var dismissed int
+	err := chromedp.Run(timeoutCtx,
+		CallFunction(`function(fields, errorSels) {
+			// 清空输入框，通过原生setter触发框架的数据绑定
+			fields.forEach((sel) => {
+				let el = null;
+				try { el = document.querySelector(sel); } catch (e) {}
+				if (!el || !('value' in el)) return;
+
+				const proto = el instanceof HTMLTextAreaElement ? HTMLTextAreaElement.prototype : HTMLInputElement.prototype;
+				const setter = Object.getOwnPropertyDescriptor(proto, 'value');
+				if (setter && setter.set && el instanceof proto.constructor) {
+					setter.set.call(el, '');
+				} else {
+					el.value = '';
+				}
+				el.dispatchEvent(new Event('input', { bubbles: true }));
+				el.dispatchEvent(new Event('change', { bubbles: true }));
+			});
+
+			// 点击错误提示中的关闭按钮
+			let dismissed = 0;
+			const closeSel = '.close, .el-message__closeBtn, .el-notification__closeBtn, .layui-layer-close, .ant-message-notice-close, .ant-alert-close-icon, [aria-label="Close"], [aria-label="close"], [data-dismiss]';
+			errorSels.forEach((sel) => {
+				let els = [];
+				try { els = document.querySelectorAll(sel); } catch (e) {}
+				els.forEach((el) => {
+					const btn = el.matches(closeSel) ? el : el.querySelector(closeSel);
+					if (btn) {
+						btn.click();
+						dismissed++;
+					}
+				});
+			});
+
+			// 关闭模态提示框
+			document.dispatchEvent(new KeyboardEvent('keydown', { key: 'Escape', keyCode: 27, bubbles: true }));
+			return dismissed;
+		}`, &dismissed, fieldSelectors, errorSelectors),
+	)
+	if err != nil {
+		return fmt.Errorf("重置登录表单失败: %v", err)
+	}
+
+	if dismissed > 0 {
+		b.logger.Debugf("已关闭 %d 个错误提示", dismissed)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package browser
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// resolveHostRe 解析覆盖允许的主机名（可带 * 通配符），不能包含空格、逗号等规则分隔符
+var resolveHostRe = regexp.MustCompile(`^[A-Za-z0-9*_.-]+$`)
+
+// SetHostResolver 设置主机名解析覆盖（主机名 -> IP），规则变化时重启浏览器使其生效
+func (b *Browser) SetHostResolver(resolve map[string]string) error {
+	for host, ip := range resolve {
+		if err := validateResolveRule(host, ip); err != nil {
+			return err
+		}
+	}
+	if sameResolve(b.resolve, resolve) {
+		return nil
+	}
+
+	b.resolve = make(map[string]string, len(resolve))
+	for host, ip := range resolve {
+		b.resolve[host] = ip
+	}
+
+	// 浏览器尚未启动时，规则会在Start时生效
+	if b.ctx == nil {
+		return nil
+	}
+
+	b.logger.Infof("🔀 主机解析规则已变化，重启浏览器: %s", hostResolverRules(b.resolve))
+	return b.Restart()
+}
+
+// HostResolver 获取当前生效的主机名解析覆盖
+func (b *Browser) HostResolver() map[string]string {
+	if len(b.resolve) == 0 {
+		return nil
+	}
+	resolve := make(map[string]string, len(b.resolve))
+	for host, ip := range b.resolve {
+		resolve[host] = ip
+	}
+	return resolve
+}
+
+// hostResolverRules 生成 --host-resolver-rules 参数值，如 "MAP example.com 10.0.0.1"
+func hostResolverRules(resolve map[string]string) string {
+	hosts := make([]string, 0, len(resolve))
+	for host := range resolve {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+
+	rules := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		ip := strings.TrimSpace(resolve[host])
+		// IPv6地址需要使用方括号
+		if parsed := net.ParseIP(ip); parsed != nil && parsed.To4() == nil {
+			ip = "[" + ip + "]"
+		}
+		rules = append(rules, fmt.Sprintf("MAP %s %s", strings.TrimSpace(host), ip))
+	}
+	return strings.Join(rules, ", ")
+}
+
+// validateResolveRule 检查解析覆盖的主机名和IP，避免值中的逗号、空格向 --host-resolver-rules 注入额外规则
+func validateResolveRule(host, ip string) error {
+	if !resolveHostRe.MatchString(strings.TrimSpace(host)) {
+		return fmt.Errorf("无效的解析覆盖主机名 %q（只允许字母、数字、-、.、_ 和 *，国际化域名请使用punycode）", host)
+	}
+	if net.ParseIP(strings.TrimSpace(ip)) == nil {
+		return fmt.Errorf("主机 %s 的解析覆盖地址 %q 不是有效的IP", host, ip)
+	}
+	return nil
+}
+
+// sameResolve 判断两组解析规则是否一致
+func sameResolve(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for host, ip := range a {
+		if b[host] != ip {
+			return false
+		}
+	}
+	return true
+}
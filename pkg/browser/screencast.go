@@ -0,0 +1,101 @@
+package browser
+
+import (
+	"context"
+	"encoding/base64"
+	"time"
+
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+)
+
+// maxScreencastFrames 单次录制最多保留的帧数
+const maxScreencastFrames = 600
+
+// ScreencastFrame 录屏中的一帧（JPEG）
+type ScreencastFrame struct {
+	Data      []byte
+	Timestamp time.Time
+}
+
+// StartScreencast 开始通过Page.startScreencast录制页面
+func (b *Browser) StartScreencast(quality, maxWidth, maxHeight, everyNthFrame int) error {
+	b.mu.Lock()
+	b.frames = nil
+	b.recording = true
+	b.mu.Unlock()
+
+	params := page.StartScreencast().WithFormat(page.ScreencastFormatJpeg)
+	if quality > 0 {
+		params = params.WithQuality(int64(quality))
+	}
+	if maxWidth > 0 {
+		params = params.WithMaxWidth(int64(maxWidth))
+	}
+	if maxHeight > 0 {
+		params = params.WithMaxHeight(int64(maxHeight))
+	}
+	if everyNthFrame > 0 {
+		params = params.WithEveryNthFrame(int64(everyNthFrame))
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(b.ctx, 5*time.Second)
+	defer cancel()
+
+	if err := chromedp.Run(timeoutCtx, params); err != nil {
+		b.mu.Lock()
+		b.recording = false
+		b.mu.Unlock()
+		return err
+	}
+	b.logger.Debug("🎬 开始录屏")
+	return nil
+}
+
+// StopScreencast 停止录屏并返回录制的帧
+func (b *Browser) StopScreencast() []ScreencastFrame {
+	b.mu.Lock()
+	wasRecording := b.recording
+	b.recording = false
+	frames := b.frames
+	b.frames = nil
+	b.mu.Unlock()
+
+	if wasRecording && b.ctx.Err() == nil {
+		timeoutCtx, cancel := context.WithTimeout(b.ctx, 5*time.Second)
+		defer cancel()
+		if err := chromedp.Run(timeoutCtx, page.StopScreencast()); err != nil {
+			b.logger.Debugf("停止录屏失败: %v", err)
+		}
+	}
+
+	b.logger.Debugf("🎬 录屏结束，共 %d 帧", len(frames))
+	return frames
+}
+
+// handleScreencastFrame 记录录屏帧并确认，Chrome收到确认后才会发送下一帧
+func (b *Browser) handleScreencastFrame(ev *page.EventScreencastFrame) {
+	ctx := b.ctx
+	go func() {
+		if err := chromedp.Run(ctx, page.ScreencastFrameAck(ev.SessionID)); err != nil {
+			b.logger.Debugf("确认录屏帧失败: %v", err)
+		}
+	}()
+
+	data, err := base64.StdEncoding.DecodeString(ev.Data)
+	if err != nil {
+		return
+	}
+
+	ts := time.Now()
+	if ev.Metadata != nil && ev.Metadata.Timestamp != nil {
+		ts = ev.Metadata.Timestamp.Time()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.recording && len(b.frames) < maxScreencastFrames {
+		b.frames = append(b.frames, ScreencastFrame{Data: data, Timestamp: ts})
+	}
+}
@@ -0,0 +1,187 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/storage"
+	"github.com/chromedp/chromedp"
+)
+
+// SessionState 登录后的会话状态（Cookie及当前源的Web Storage）
+type SessionState struct {
+	URL            string            `json:"url"`
+	Origin         string            `json:"origin"`
+	Cookies        []*network.Cookie `json:"cookies"`
+	LocalStorage   map[string]string `json:"local_storage"`
+	SessionStorage map[string]string `json:"session_storage"`
+	CapturedAt     time.Time         `json:"captured_at"`
+}
+
+// CaptureSession 采集当前浏览器的全部Cookie及当前页面源的localStorage/sessionStorage
+func (b *Browser) CaptureSession() (*SessionState, error) {
+	timeoutCtx, cancel := context.WithTimeout(b.ctx, 10*time.Second)
+	defer cancel()
+
+	state := &SessionState{CapturedAt: time.Now()}
+	var storageJSON struct {
+		Origin         string            `json:"origin"`
+		LocalStorage   map[string]string `json:"local"`
+		SessionStorage map[string]string `json:"session"`
+	}
+
+	err := chromedp.Run(timeoutCtx,
+		chromedp.Location(&state.URL),
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			// Storage.getCookies需要在浏览器级别执行，可获取所有域名的Cookie
+			browserCtx := cdp.WithExecutor(ctx, chromedp.FromContext(ctx).Browser)
+			cookies, err := storage.GetCookies().Do(browserCtx)
+			if err != nil {
+				return fmt.Errorf("获取Cookie失败: %v", err)
+			}
+			state.Cookies = cookies
+			return nil
+		}),
+		chromedp.Evaluate(`(() => {
+			const dump = (store) => {
+				const data = {};
+				try {
+					for (let i = 0; i < store.length; i++) {
+						const key = store.key(i);
+						data[key] = store.getItem(key);
+					}
+				} catch (e) {}
+				return data;
+			};
+			return { origin: location.origin, local: dump(localStorage), session: dump(sessionStorage) };
+		})()`, &storageJSON),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("采集会话状态失败: %v", err)
+	}
+
+	state.Origin = storageJSON.Origin
+	state.LocalStorage = storageJSON.LocalStorage
+	state.SessionStorage = storageJSON.SessionStorage
+	return state, nil
+}
+
+// RestoreSession 将会话状态写入当前浏览器并打开登录后的页面
+func (b *Browser) RestoreSession(state *SessionState) error {
+	timeoutCtx, cancel := context.WithTimeout(b.ctx, time.Duration(b.config.Browser.Timeout)*time.Second)
+	defer cancel()
+
+	// 写入Cookie后打开登录后的页面；有Web Storage时在该源写入后重新加载，页面脚本即可读取
+	var written int
+	actions := []chromedp.Action{
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			if err := network.SetCookies(cookieParams(state.Cookies)).Do(ctx); err != nil {
+				return fmt.Errorf("写入Cookie失败: %v", err)
+			}
+			return nil
+		}),
+		chromedp.Navigate(state.URL),
+	}
+	if len(state.LocalStorage)+len(state.SessionStorage) > 0 {
+		actions = append(actions,
+			CallFunction(restoreStorageScript, &written, sessionOrigin(state), state.LocalStorage, state.SessionStorage),
+			chromedp.ActionFunc(func(ctx context.Context) error {
+				if written < 0 {
+					b.logger.Warnf("当前页面不属于会话源 %s，未恢复Web Storage", sessionOrigin(state))
+					return nil
+				}
+				return chromedp.Reload().Do(ctx)
+			}),
+		)
+	}
+
+	if err := chromedp.Run(timeoutCtx, actions...); err != nil {
+		return fmt.Errorf("恢复会话失败: %v", err)
+	}
+	return nil
+}
+
+// Handoff 启动可见的Chrome窗口，复用当前浏览器的解析覆盖和模拟配置，恢复会话后打开登录后的页面
+func (b *Browser) Handoff(state *SessionState) (*Browser, error) {
+	cfg := *b.config
+	cfg.Browser.Headless = false
+	cfg.Browser.Launch.UserDataDir = ""  // 使用独立的临时目录，避免与当前实例冲突
+	cfg.Browser.Blocking.Enabled = false // 测试人员需要看到完整页面
+
+	visible := NewBrowser(&cfg, b.logger)
+	visible.resolve = b.HostResolver()
+	if err := visible.Start(); err != nil {
+		return nil, fmt.Errorf("启动可见浏览器失败: %v", err)
+	}
+	if b.profile != nil {
+		if err := visible.ApplyEmulation(b.profileName, b.profile); err != nil {
+			visible.Close()
+			return nil, err
+		}
+	}
+	if err := visible.RestoreSession(state); err != nil {
+		visible.Close()
+		return nil, err
+	}
+
+	b.logger.Infof("🖐️ 已在可见浏览器中恢复会话: %s", state.URL)
+	return visible, nil
+}
+
+// Done 浏览器关闭（窗口被关闭或进程退出）时关闭的通道
+func (b *Browser) Done() <-chan struct{} {
+	return b.ctx.Done()
+}
+
+// cookieParams 将采集到的Cookie转换为Network.setCookies参数
+func cookieParams(cookies []*network.Cookie) []*network.CookieParam {
+	params := make([]*network.CookieParam, 0, len(cookies))
+	for _, c := range cookies {
+		param := &network.CookieParam{
+			Name:         c.Name,
+			Value:        c.Value,
+			Domain:       c.Domain,
+			Path:         c.Path,
+			Secure:       c.Secure,
+			HTTPOnly:     c.HTTPOnly,
+			SameSite:     c.SameSite,
+			Priority:     c.Priority,
+			SameParty:    c.SameParty,
+			SourceScheme: c.SourceScheme,
+			SourcePort:   c.SourcePort,
+			PartitionKey: c.PartitionKey,
+		}
+		if !c.Session && c.Expires > 0 {
+			expires := cdp.TimeSinceEpoch(time.Unix(0, int64(c.Expires*float64(time.Second))))
+			param.Expires = &expires
+		}
+		params = append(params, param)
+	}
+	return params
+}
+
+// restoreStorageScript 在目标源写入localStorage/sessionStorage，返回写入的条目数，页面不属于该源时返回-1
+const restoreStorageScript = `function(origin, local, session) {
+	if (location.origin !== origin) return -1;
+	let count = 0;
+	try {
+		Object.keys(local || {}).forEach((k) => { localStorage.setItem(k, local[k]); count++; });
+		Object.keys(session || {}).forEach((k) => { sessionStorage.setItem(k, session[k]); count++; });
+	} catch (e) {}
+	return count;
+}`
+
+// sessionOrigin 获取会话所属的源，采集时未记录则从URL推断
+func sessionOrigin(state *SessionState) string {
+	if state.Origin != "" {
+		return state.Origin
+	}
+	if u, err := url.Parse(state.URL); err == nil {
+		return u.Scheme + "://" + u.Host
+	}
+	return ""
+}
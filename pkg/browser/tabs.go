@@ -0,0 +1,78 @@
+package browser
+
+import (
+	"context"
+	"time"
+
+	"github.com/chromedp/cdproto/target"
+	"github.com/chromedp/chromedp"
+)
+
+// TabInfo 新打开标签页的页面信息
+type TabInfo struct {
+	ID      target.ID `json:"-"`
+	Title   string    `json:"title"`
+	URL     string    `json:"url"`
+	Content string    `json:"-"`
+}
+
+// OpenedTabs 获取本次尝试中由当前页面打开的新标签页
+func (b *Browser) OpenedTabs() []target.ID {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	tabs := make([]target.ID, len(b.openedTabs))
+	copy(tabs, b.openedTabs)
+	return tabs
+}
+
+// InspectTab 连接到新打开的标签页并读取其页面信息
+func (b *Browser) InspectTab(id target.ID) (*TabInfo, error) {
+	b.mu.Lock()
+	tabCtx, ok := b.tabContexts[id]
+	if !ok {
+		var cancel context.CancelFunc
+		tabCtx, cancel = chromedp.NewContext(b.ctx, chromedp.WithTargetID(id))
+		b.tabContexts[id] = tabCtx
+		b.tabCancels[id] = cancel
+	}
+	b.mu.Unlock()
+
+	timeoutCtx, cancel := context.WithTimeout(tabCtx, 10*time.Second)
+	defer cancel()
+
+	info := &TabInfo{ID: id}
+	err := chromedp.Run(timeoutCtx,
+		chromedp.WaitReady("body", chromedp.ByQuery),
+		chromedp.Title(&info.Title),
+		chromedp.Location(&info.URL),
+		chromedp.Text("body", &info.Content, chromedp.ByQuery),
+	)
+
+	return info, err
+}
+
+// CloseOpenedTabs 关闭本次尝试中打开的所有新标签页，保证后续尝试在干净的状态下进行
+func (b *Browser) CloseOpenedTabs() {
+	b.mu.Lock()
+	tabs := b.openedTabs
+	cancels := b.tabCancels
+	b.openedTabs = nil
+	b.tabContexts = make(map[target.ID]context.Context)
+	b.tabCancels = make(map[target.ID]context.CancelFunc)
+	b.mu.Unlock()
+
+	for _, id := range tabs {
+		// 已连接的标签页在取消上下文时由chromedp关闭
+		if cancel, ok := cancels[id]; ok {
+			cancel()
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(b.ctx, 3*time.Second)
+		if err := chromedp.Run(ctx, target.CloseTarget(id)); err != nil {
+			b.logger.Debugf("关闭标签页失败: %v", err)
+		}
+		cancel()
+	}
+}
@@ -0,0 +1,151 @@
+package browser
+
+import (
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/chromedp/cdproto/network"
+)
+
+// TLS证书问题类型
+const (
+	CertErrorExpired          = "expired"           // 证书已过期
+	CertErrorNotYetValid      = "not_yet_valid"     // 证书尚未生效
+	CertErrorSelfSigned       = "self_signed"       // 自签名证书
+	CertErrorHostnameMismatch = "hostname_mismatch" // 证书域名与访问域名不匹配
+)
+
+// TLSInfo 主文档的TLS连接及证书信息
+type TLSInfo struct {
+	URL           string    `json:"url"`
+	SecurityState string    `json:"security_state"`
+	Protocol      string    `json:"protocol"`
+	KeyExchange   string    `json:"key_exchange,omitempty"`
+	Cipher        string    `json:"cipher"`
+	Subject       string    `json:"subject"`
+	Issuer        string    `json:"issuer"`
+	ValidFrom     time.Time `json:"valid_from"`
+	ValidTo       time.Time `json:"valid_to"`
+	SANs          []string  `json:"sans,omitempty"`
+	NetworkError  string    `json:"network_error,omitempty"` // Chrome报告的证书错误码，如 net::ERR_CERT_DATE_INVALID
+	Errors        []string  `json:"errors,omitempty"`        // 本地检查得到的证书问题
+	WeakSignature bool      `json:"weak_signature,omitempty"`
+	ObsoleteTLS   bool      `json:"obsolete_tls,omitempty"`
+	CTCompliance  string    `json:"certificate_transparency,omitempty"`
+}
+
+// HasCertificateError 证书是否存在问题
+func (t *TLSInfo) HasCertificateError() bool {
+	return t != nil && (len(t.Errors) > 0 || t.NetworkError != "")
+}
+
+// CertificateProblems 汇总证书问题（本地检查结果及Chrome报告的错误码）
+func (t *TLSInfo) CertificateProblems() []string {
+	if t == nil {
+		return nil
+	}
+	problems := append([]string(nil), t.Errors...)
+	if t.NetworkError != "" {
+		problems = append(problems, t.NetworkError)
+	}
+	return problems
+}
+
+// TLSInfo 获取最近一次主文档响应的TLS信息，非HTTPS页面返回nil
+func (b *Browser) TLSInfo() *TLSInfo {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.tls == nil {
+		return nil
+	}
+	info := *b.tls
+	info.SANs = append([]string(nil), b.tls.SANs...)
+	info.Errors = append([]string(nil), b.tls.Errors...)
+
+	// 合并Security域报告的证书状态（仅当证书与主文档一致时）
+	if state := b.certState; state != nil && state.SubjectName == info.Subject {
+		info.NetworkError = state.CertificateNetworkError
+		info.WeakSignature = state.CertificateHasWeakSignature || state.CertificateHasSha1signature
+		info.ObsoleteTLS = state.ObsoleteSslProtocol || state.ObsoleteSslCipher || state.ObsoleteSslKeyExchange
+	}
+	return &info
+}
+
+// newTLSInfo 从主文档响应中提取TLS信息
+func newTLSInfo(resp *network.Response) *TLSInfo {
+	details := resp.SecurityDetails
+	if details == nil {
+		return nil
+	}
+
+	info := &TLSInfo{
+		URL:           resp.URL,
+		SecurityState: resp.SecurityState.String(),
+		Protocol:      details.Protocol,
+		KeyExchange:   details.KeyExchange,
+		Cipher:        details.Cipher,
+		Subject:       details.SubjectName,
+		Issuer:        details.Issuer,
+		SANs:          details.SanList,
+		CTCompliance:  details.CertificateTransparencyCompliance.String(),
+	}
+	if details.ValidFrom != nil {
+		info.ValidFrom = details.ValidFrom.Time()
+	}
+	if details.ValidTo != nil {
+		info.ValidTo = details.ValidTo.Time()
+	}
+
+	host := ""
+	if u, err := url.Parse(resp.URL); err == nil {
+		host = u.Hostname()
+	}
+	info.Errors = checkCertificate(info, host, time.Now())
+	return info
+}
+
+// checkCertificate 检查证书有效期、自签名和域名匹配情况
+func checkCertificate(info *TLSInfo, host string, now time.Time) []string {
+	var errors []string
+
+	if !info.ValidTo.IsZero() && now.After(info.ValidTo) {
+		errors = append(errors, CertErrorExpired)
+	}
+	if !info.ValidFrom.IsZero() && now.Before(info.ValidFrom) {
+		errors = append(errors, CertErrorNotYetValid)
+	}
+	if info.Subject != "" && info.Subject == info.Issuer {
+		errors = append(errors, CertErrorSelfSigned)
+	}
+	if host != "" && !certificateMatchesHost(info.SANs, info.Subject, host) {
+		errors = append(errors, CertErrorHostnameMismatch)
+	}
+
+	return errors
+}
+
+// certificateMatchesHost 判断证书SAN（无SAN时使用主题名）是否覆盖访问的主机名
+func certificateMatchesHost(sans []string, subject, host string) bool {
+	names := sans
+	if len(names) == 0 && subject != "" {
+		names = []string{subject}
+	}
+
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSuffix(name, "."))
+		if name == host {
+			return true
+		}
+		// 通配符仅匹配一级子域名
+		if strings.HasPrefix(name, "*.") && net.ParseIP(host) == nil {
+			if i := strings.Index(host, "."); i > 0 && host[i+1:] == name[2:] {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,236 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/dom"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+)
+
+// maxVisibilityChecks 每条规则最多检查的节点数
+const maxVisibilityChecks = 20
+
+// HiddenStateScript 页面脚本片段（箭头函数），返回元素的隐藏原因和是否疑似蜜罐，供其他页面脚本内联使用。
+// display:none、visibility:hidden属于普通隐藏；透明、极小尺寸、移出页面、aria-hidden、
+// tabindex=-1和裁剪这类"看不见但可填写"的手段视为蜜罐特征。
+// 复选框和单选框常被UI库隐藏后用样式化的<label>代替（如Element UI、Ant Design），
+// 透明、尺寸和裁剪不算蜜罐特征，有可见的关联<label>时视为可见（labelled为true）
+const HiddenStateScript = `(el) => {
+	const reasons = [];
+	let honeypot = false, styled = false;
+	const toggle = el.tagName === 'INPUT' && ['checkbox', 'radio'].includes((el.type || '').toLowerCase());
+	const trick = (reason) => {
+		reasons.push(reason);
+		honeypot = true;
+	};
+	// 复选框、单选框被样式替换的常见手段
+	const restyled = (reason) => {
+		if (!toggle) return trick(reason);
+		reasons.push(reason);
+		styled = true;
+	};
+
+	if (el.tagName === 'INPUT' && (el.type || '').toLowerCase() === 'hidden') {
+		return { reasons: ['type=hidden'], honeypot: false, labelled: false };
+	}
+
+	const style = getComputedStyle(el);
+	if (style.display === 'none') reasons.push('display:none');
+	if (style.visibility === 'hidden' || style.visibility === 'collapse') reasons.push('visibility:hidden');
+	if (parseFloat(style.opacity) < 0.1) restyled('opacity:' + style.opacity);
+	if (style.clip === 'rect(0px, 0px, 0px, 0px)' || style.clipPath === 'inset(50%)') restyled('clip');
+
+	for (let cur = el.parentElement; cur && cur !== document.body; cur = cur.parentElement) {
+		const s = getComputedStyle(cur);
+		if (s.display === 'none') {
+			reasons.push('父元素display:none');
+			break;
+		}
+		if (parseFloat(s.opacity) < 0.1) {
+			trick('父元素opacity:' + s.opacity);
+			break;
+		}
+	}
+
+	const rect = el.getBoundingClientRect();
+	if (style.display !== 'none' && reasons.indexOf('父元素display:none') < 0) {
+		if (rect.width < 2 || rect.height < 2) restyled('尺寸' + Math.round(rect.width) + 'x' + Math.round(rect.height));
+		const docWidth = Math.max(document.documentElement.scrollWidth, window.innerWidth);
+		const docHeight = Math.max(document.documentElement.scrollHeight, window.innerHeight);
+		const left = rect.left + window.scrollX, top = rect.top + window.scrollY;
+		if (left + rect.width <= 0 || top + rect.height <= 0 || left >= docWidth || top >= docHeight) trick('位于页面外');
+	}
+
+	if (el.closest('[aria-hidden="true"]')) trick('aria-hidden');
+	if (el.getAttribute('tabindex') === '-1') trick('tabindex=-1');
+
+	// 只因样式替换而看不见的复选框、单选框，由可见的关联<label>代为展示和点击
+	if (styled && !honeypot && reasons.length > 0) {
+		const shown = (label) => {
+			const s = getComputedStyle(label);
+			const r = label.getBoundingClientRect();
+			return s.display !== 'none' && s.visibility !== 'hidden' && parseFloat(s.opacity) >= 0.1 && r.width >= 2 && r.height >= 2;
+		};
+		const hidden = reasons.some((r) => r === 'display:none' || r === 'visibility:hidden' || r === '父元素display:none');
+		if (!hidden && Array.from(el.labels || []).some(shown)) {
+			return { reasons: [], honeypot: false, labelled: true };
+		}
+	}
+
+	return { reasons: reasons, honeypot: honeypot, labelled: false };
+}`
+
+// Visibility 元素可见性检查结果
+type Visibility struct {
+	Visible  bool     `json:"visible"`
+	Honeypot bool     `json:"honeypot"`          // 看不见但可填写，疑似蜜罐字段
+	Reasons  []string `json:"reasons,omitempty"` // 不可见的原因
+}
+
+// CheckVisibility 结合DOM盒模型和计算样式检查节点是否对用户可见
+func (b *Browser) CheckVisibility(ref *ElementRef) (*Visibility, error) {
+	timeoutCtx, cancel := context.WithTimeout(b.ctx, 5*time.Second)
+	defer cancel()
+
+	var vis *Visibility
+	err := chromedp.Run(timeoutCtx, chromedp.ActionFunc(func(ctx context.Context) error {
+		var err error
+		vis, err = checkVisibility(ctx, ref.BackendNodeID)
+		return err
+	}))
+	if err != nil {
+		return nil, fmt.Errorf("检查元素可见性失败: %v", err)
+	}
+	return vis, nil
+}
+
+// FindVisibleElement 按规则顺序查找第一个可见的节点，跳过隐藏的重复元素和蜜罐字段
+func (b *Browser) FindVisibleElement(selectors []string) (*ElementRef, error) {
+	timeoutCtx, cancel := context.WithTimeout(b.ctx, 5*time.Second)
+	defer cancel()
+
+	var ref *ElementRef
+	err := chromedp.Run(timeoutCtx, chromedp.ActionFunc(func(ctx context.Context) error {
+		for _, selector := range selectors {
+			base, text := splitContains(selector)
+			var nodes []*cdp.Node
+			if err := chromedp.Nodes(base, &nodes, chromedp.ByQueryAll, chromedp.AtLeast(0)).Do(ctx); err != nil {
+				continue
+			}
+
+			checked := 0
+			for i, node := range nodes {
+				if text != "" {
+					if content, err := nodeText(ctx, node.BackendNodeID); err != nil || !strings.Contains(content, text) {
+						continue
+					}
+				}
+				if checked++; checked > maxVisibilityChecks {
+					break
+				}
+				vis, err := checkVisibility(ctx, node.BackendNodeID)
+				if err != nil {
+					return err
+				}
+				if !vis.Visible {
+					b.logger.Debugf("跳过不可见元素 %s[%d]: %v", selector, i, vis.Reasons)
+					continue
+				}
+
+				unique, err := uniqueSelector(ctx, node.BackendNodeID)
+				if err != nil {
+					return err
+				}
+				ref = &ElementRef{Selector: unique, BackendNodeID: node.BackendNodeID}
+				return nil
+			}
+		}
+		return nil
+	}))
+	if err != nil {
+		return nil, fmt.Errorf("查找可见元素失败: %v", err)
+	}
+
+	if ref != nil {
+		b.logger.Debugf("找到可见元素: %s", ref.Selector)
+	}
+	return ref, nil
+}
+
+// containsRe 匹配jQuery风格的 :contains("文本") 伪类，它不是标准CSS
+var containsRe = regexp.MustCompile(`^(.*):contains\(\s*["']?(.*?)["']?\s*\)$`)
+
+// splitContains 把 :contains("文本") 规则拆成基础选择器和要求包含的文本
+func splitContains(selector string) (string, string) {
+	m := containsRe.FindStringSubmatch(strings.TrimSpace(selector))
+	if m == nil {
+		return selector, ""
+	}
+	if m[1] == "" {
+		return "*", m[2]
+	}
+	return m[1], m[2]
+}
+
+// nodeText 返回节点的文本内容，按钮类input返回value
+func nodeText(ctx context.Context, id cdp.BackendNodeID) (string, error) {
+	obj, err := dom.ResolveNode().WithBackendNodeID(id).Do(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = runtime.ReleaseObject(obj.ObjectID).Do(ctx)
+	}()
+
+	var text string
+	err = chromedp.CallFunctionOn(`function() { return this.textContent || this.value || ''; }`, &text, func(p *runtime.CallFunctionOnParams) *runtime.CallFunctionOnParams {
+		return p.WithObjectID(obj.ObjectID)
+	}).Do(ctx)
+	return text, err
+}
+
+// checkVisibility 先在页面中检查计算样式，再查询盒模型（未渲染的节点没有盒模型）；
+// 由可见<label>代替展示的复选框本身可以没有尺寸，不检查盒模型
+func checkVisibility(ctx context.Context, id cdp.BackendNodeID) (*Visibility, error) {
+	vis := &Visibility{}
+
+	obj, err := dom.ResolveNode().WithBackendNodeID(id).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = runtime.ReleaseObject(obj.ObjectID).Do(ctx)
+	}()
+
+	var state struct {
+		Reasons  []string `json:"reasons"`
+		Honeypot bool     `json:"honeypot"`
+		Labelled bool     `json:"labelled"`
+	}
+	err = chromedp.CallFunctionOn(`function() { return (`+HiddenStateScript+`)(this); }`, &state, func(p *runtime.CallFunctionOnParams) *runtime.CallFunctionOnParams {
+		return p.WithObjectID(obj.ObjectID)
+	}).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !state.Labelled {
+		box, err := dom.GetBoxModel().WithBackendNodeID(id).Do(ctx)
+		if err != nil {
+			vis.Reasons = append(vis.Reasons, "无盒模型")
+		} else if box.Width == 0 || box.Height == 0 {
+			vis.Reasons = append(vis.Reasons, fmt.Sprintf("盒模型尺寸%dx%d", box.Width, box.Height))
+		}
+	}
+
+	vis.Reasons = append(vis.Reasons, state.Reasons...)
+	vis.Honeypot = state.Honeypot
+	vis.Visible = len(vis.Reasons) == 0
+	return vis, nil
+}
@@ -2,6 +2,7 @@ package bruteforce
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/cyberspacesec/chrome_auto_login/pkg/browser"
@@ -10,14 +11,53 @@ import (
 	"github.com/cyberspacesec/chrome_auto_login/util"
 )
 
+// failureKeywords 登录失败关键词
+var failureKeywords = []string{
+	"密码错误", "用户名错误", "登录失败", "认证失败", "invalid", "error",
+	"incorrect", "failed", "wrong", "验证码", "captcha", "验证失败",
+	"用户名或密码",
+}
+
+// messageFailureKeywords 对话框和错误提示使用的失败关键词。额外的几个词在登录后页面中也很常见
+// （如"记录不存在"），只用于匹配提示信息，不用于匹配整个页面内容
+var messageFailureKeywords = append([]string{"不正确", "不存在", "已锁定"}, failureKeywords...)
+
+// LoginOutcome 登录尝试结果类型
+type LoginOutcome string
+
+const (
+	OutcomeSuccess   LoginOutcome = "success"   // 登录成功
+	OutcomeSuspected LoginOutcome = "suspected" // 疑似成功（无明确成功或失败标识）
+	OutcomeFailure   LoginOutcome = "failure"   // 登录失败
+	OutcomeError     LoginOutcome = "error"     // 尝试过程出错
+)
+
 // BruteForceResult 爆破结果
 type BruteForceResult struct {
-	Success      bool
-	Username     string
-	Password     string
-	ErrorMessage string
-	URL          string
-	Screenshot   []byte
+	Success        bool                       `json:"success"`
+	Outcome        LoginOutcome               `json:"outcome"`
+	Username       string                     `json:"username"`
+	Password       string                     `json:"password"`
+	ErrorMessage   string                     `json:"error_message,omitempty"`
+	TargetURL      string                     `json:"target_url"`
+	URL            string                     `json:"url"`
+	Timestamp      time.Time                  `json:"timestamp"`
+	Screenshot     []byte                     `json:"-"`
+	ScreenshotPath string                     `json:"screenshot_path,omitempty"`
+	PageTitle      string                     `json:"page_title,omitempty"`
+	RedirectChain  []browser.NavigationHop    `json:"redirect_chain,omitempty"`
+	EvidencePath   string                     `json:"evidence_path,omitempty"`
+	RecordingPath  string                     `json:"recording_path,omitempty"` // 本次尝试的录屏
+	Session        *browser.SessionState      `json:"-"`                        // 登录成功后的会话状态
+	SessionPath    string                     `json:"session_path,omitempty"`   // 会话导出目录
+	IdP            *detector.IdentityProvider `json:"idp,omitempty"`            // 目标登录所依赖的统一认证服务
+	Messages       []string                   `json:"messages,omitempty"`       // 对话框及错误提示区域中的文本
+	OpenedTabs     []string                   `json:"opened_tabs,omitempty"`    // 提交后新打开的标签页URL
+	Profile        string                     `json:"profile,omitempty"`        // 使用的设备/区域模拟配置档
+	TLS            *browser.TLSInfo           `json:"tls,omitempty"`            // 目标页面的TLS及证书信息
+	Resolve        map[string]string          `json:"resolve,omitempty"`        // 生效的主机名解析覆盖
+	Console        []browser.ConsoleMessage   `json:"console,omitempty"`        // 结果不确定时附带的控制台消息和JS异常
+	Completed      bool                       `json:"completed"`                // 凭据已全部尝试或找到有效凭据，未因检测失败等原因提前结束
 }
 
 // BruteForceEngine 爆破引擎
@@ -31,6 +71,9 @@ type BruteForceEngine struct {
 	progressBar   *util.ProgressBar
 	isSuccess     bool
 	successResult *BruteForceResult
+	attempts      int    // 自上次启动浏览器以来的登录尝试次数
+	sinceReload   int    // 自上次加载登录页面以来的登录尝试次数
+	fingerprint   string // 登录表单结构指纹
 }
 
 // NewBruteForceEngine 创建爆破引擎
@@ -43,6 +86,7 @@ func NewBruteForceEngine(browser *browser.Browser, detector *detector.PageDetect
 		cfg.Results.SaveDir,
 		cfg.Results.SuccessFilenameFormat,
 		cfg.Results.FailureFilenameFormat,
+		cfg.Results.RecordFilenameFormat,
 		cfg.Results.Format,
 		cfg.Results.RealtimeSave,
 	)
@@ -67,12 +111,19 @@ func (b *BruteForceEngine) ExecuteBruteForce(targetURL string) (*BruteForceResul
 		return nil, fmt.Errorf("导航到目标URL失败: %v", err)
 	}
 
+	// 记录登录页面的TLS信息，证书问题本身即为一项发现
+	targetTLS := b.browser.TLSInfo()
+	if targetTLS.HasCertificateError() {
+		b.logger.Warn(fmt.Sprintf("🔓 目标证书存在问题: %s", strings.Join(targetTLS.CertificateProblems(), ", ")))
+	}
+
 	// 检测是否为登录页面
 	isLogin, err := b.detector.IsLoginPage()
 	if err != nil {
 		return &BruteForceResult{
 			Success:      false,
 			ErrorMessage: fmt.Sprintf("检测登录页面失败: %v", err),
+			TargetURL:    targetURL,
 			URL:          targetURL,
 		}, nil
 	}
@@ -82,18 +133,26 @@ func (b *BruteForceEngine) ExecuteBruteForce(targetURL string) (*BruteForceResul
 		return &BruteForceResult{
 			Success:      false,
 			ErrorMessage: "目标页面不是登录页面，已自动跳过",
+			TargetURL:    targetURL,
 			URL:          targetURL,
 		}, nil
 	}
 
 	b.logger.Info("✅ 确认为登录页面，继续执行爆破")
 
+	// 登录页面位于跳转后的哈希路由时，后续重新加载使用该地址
+	if current, err := b.browser.GetCurrentURL(); err == nil && current != targetURL && stripFragment(current) == stripFragment(targetURL) {
+		b.logger.Info(fmt.Sprintf("🔀 登录页面位于哈希路由 %s，后续尝试使用该地址", current))
+		targetURL = current
+	}
+
 	// 检测登录表单元素
 	formElements, err := b.detector.DetectLoginForm()
 	if err != nil {
 		return &BruteForceResult{
 			Success:      false,
 			ErrorMessage: fmt.Sprintf("检测登录表单失败: %v", err),
+			TargetURL:    targetURL,
 			URL:          targetURL,
 		}, nil
 	}
@@ -104,24 +163,27 @@ func (b *BruteForceEngine) ExecuteBruteForce(targetURL string) (*BruteForceResul
 		return &BruteForceResult{
 			Success:      false,
 			ErrorMessage: "未找到用户名输入框，无法执行爆破",
+			TargetURL:    targetURL,
 			URL:          targetURL,
 		}, nil
 	}
 
-	if formElements.PasswordSelector == "" {
+	if formElements.PasswordSelector == "" && !formElements.MultiStep {
 		b.logger.Warn("⚠️ 未找到密码输入框")
 		return &BruteForceResult{
 			Success:      false,
 			ErrorMessage: "未找到密码输入框，无法执行爆破",
+			TargetURL:    targetURL,
 			URL:          targetURL,
 		}, nil
 	}
 
-	if formElements.SubmitSelector == "" {
+	if formElements.SubmitSelector == "" && !formElements.MultiStep {
 		b.logger.Warn("⚠️ 未找到提交按钮")
 		return &BruteForceResult{
 			Success:      false,
 			ErrorMessage: "未找到提交按钮，无法执行爆破",
+			TargetURL:    targetURL,
 			URL:          targetURL,
 		}, nil
 	}
@@ -145,6 +207,7 @@ func (b *BruteForceEngine) ExecuteBruteForce(targetURL string) (*BruteForceResul
 				return &BruteForceResult{
 					Success:      false,
 					ErrorMessage: fmt.Sprintf("目标站点包含%s，已配置跳过", formElements.CaptchaInfo.GetTypeName()),
+					TargetURL:    targetURL,
 					URL:          targetURL,
 				}, nil
 			}
@@ -153,6 +216,7 @@ func (b *BruteForceEngine) ExecuteBruteForce(targetURL string) (*BruteForceResul
 			return &BruteForceResult{
 				Success:      false,
 				ErrorMessage: "目标站点包含验证码，已配置跳过",
+				TargetURL:    targetURL,
 				URL:          targetURL,
 			}, nil
 		}
@@ -164,6 +228,7 @@ func (b *BruteForceEngine) ExecuteBruteForce(targetURL string) (*BruteForceResul
 		return &BruteForceResult{
 			Success:      false,
 			ErrorMessage: "没有可用的用户名密码组合",
+			TargetURL:    targetURL,
 			URL:          targetURL,
 		}, nil
 	}
@@ -180,6 +245,10 @@ func (b *BruteForceEngine) ExecuteBruteForce(targetURL string) (*BruteForceResul
 	fmt.Printf("🎯 凭据组合: %d 组\n", len(credentials))
 	fmt.Printf("⏱️  间隔时间: %d 秒\n\n", b.config.Bruteforce.Delay)
 
+	// 记录登录表单指纹，用于判断页面是否需要重新加载
+	b.rememberForm(formElements)
+	b.sinceReload = 0
+
 	// 逐一尝试凭据
 	for i, cred := range credentials {
 		// 检查是否已经成功
@@ -187,6 +256,13 @@ func (b *BruteForceEngine) ExecuteBruteForce(targetURL string) (*BruteForceResul
 			break
 		}
 
+		// 按重置策略准备登录页面
+		if i > 0 {
+			formElements = b.prepareAttempt(targetURL, formElements)
+		} else {
+			b.sinceReload++
+		}
+
 		// 显示即将尝试的凭据
 		b.logger.Info(fmt.Sprintf("🔑 正在尝试第 %d/%d 组凭据: 用户名=%s, 密码=%s", i+1, len(credentials), cred.Username, cred.Password))
 
@@ -194,10 +270,39 @@ func (b *BruteForceEngine) ExecuteBruteForce(targetURL string) (*BruteForceResul
 		progressMsg := fmt.Sprintf("尝试 %s:%s", cred.Username, cred.Password)
 		b.progressBar.Update(i+1, progressMsg)
 
-		result, err := b.tryLogin(formElements, cred, targetURL)
+		result, err := b.attempt(formElements, cred, targetURL)
+
+		// 浏览器崩溃或标签页卡死时重启浏览器并重试当前凭据
+		maxRestarts := b.config.GetMaxCrashRestarts()
+		for restart := 1; err != nil && restart <= maxRestarts && !b.browser.Alive(); restart++ {
+			b.logger.Warn(fmt.Sprintf("💥 浏览器不可用(%s)，重启后重试当前凭据 (%d/%d)", b.browser.CrashReason(), restart, maxRestarts))
+			elements, restartErr := b.restartBrowser(targetURL, formElements)
+			if restartErr != nil {
+				b.logger.Warn(fmt.Sprintf("⚠️  %v", restartErr))
+				continue
+			}
+			formElements = elements
+			result, err = b.attempt(formElements, cred, targetURL)
+		}
+
 		if err != nil {
 			b.logger.Warn(fmt.Sprintf("❌ 登录尝试失败: %v", err))
 			b.status.UpdateAttempt(cred.Username, cred.Password, false)
+			if result == nil {
+				result = &BruteForceResult{
+					Username:     cred.Username,
+					Password:     cred.Password,
+					ErrorMessage: err.Error(),
+					TargetURL:    targetURL,
+					URL:          targetURL,
+				}
+			}
+			result.Outcome = OutcomeError
+			result.Timestamp = time.Now()
+			b.annotateResult(result, targetTLS)
+			b.saveRecording(result)
+			b.captureEvidence(result)
+			b.recordResult(result)
 			// 记录失败结果
 			b.resultLogger.LogFailure(targetURL, cred.Username, cred.Password)
 			continue
@@ -206,9 +311,18 @@ func (b *BruteForceEngine) ExecuteBruteForce(targetURL string) (*BruteForceResul
 		// 更新状态
 		b.status.UpdateAttempt(cred.Username, cred.Password, result.Success)
 
+		// 保存截图等证据
+		b.annotateResult(result, targetTLS)
+		b.saveRecording(result)
+		b.captureEvidence(result)
+		b.captureSession(result)
+		b.exportSession(result)
+		b.recordResult(result)
+
 		if result.Success {
 			b.isSuccess = true
 			b.successResult = result
+			result.Completed = true
 			b.progressBar.Finish("🎉 爆破成功！")
 
 			// 记录成功结果
@@ -235,12 +349,13 @@ func (b *BruteForceEngine) ExecuteBruteForce(targetURL string) (*BruteForceResul
 			}
 		}
 
-		// 重新导航到登录页面（如果需要）
-		currentURL, _ := b.browser.GetCurrentURL()
-		if currentURL != targetURL {
-			if err := b.browser.NavigateTo(targetURL); err != nil {
-				b.logger.Debug(fmt.Sprintf("重新导航到登录页面失败: %v", err))
-				continue
+		// 定期重启浏览器，限制长时间运行的内存增长
+		if n := b.config.Browser.RecycleEveryNAttempts; n > 0 && b.attempts >= n && i < len(credentials)-1 {
+			b.logger.Info(fmt.Sprintf("♻️  已完成 %d 次尝试，重启浏览器", b.attempts))
+			if elements, err := b.restartBrowser(targetURL, formElements); err != nil {
+				b.logger.Warn(fmt.Sprintf("⚠️  %v", err))
+			} else {
+				formElements = elements
 			}
 		}
 	}
@@ -251,30 +366,85 @@ func (b *BruteForceEngine) ExecuteBruteForce(targetURL string) (*BruteForceResul
 	return &BruteForceResult{
 		Success:      false,
 		ErrorMessage: "所有凭据尝试失败",
+		TargetURL:    targetURL,
 		URL:          targetURL,
+		Completed:    true,
 	}, nil
 }
 
+// annotateResult 为尝试结果补充浏览器环境信息
+func (b *BruteForceEngine) annotateResult(result *BruteForceResult, targetTLS *browser.TLSInfo) {
+	result.Profile = b.browser.EmulationProfile()
+	result.TLS = targetTLS
+	result.Resolve = b.browser.HostResolver()
+
+	// 结果不确定时附带控制台消息，表单未提交的原因通常是JS错误
+	if result.Outcome == OutcomeSuspected || result.Outcome == OutcomeError {
+		result.Console = b.browser.ConsoleMessages()
+		for _, msg := range b.browser.ConsoleErrors() {
+			b.logger.Debug(fmt.Sprintf("🖥️  %s", msg))
+		}
+	}
+}
+
+// attempt 尝试一组凭据。提交后浏览器崩溃时无法判断登录结果，按出错处理以便重试该凭据
+func (b *BruteForceEngine) attempt(elements *detector.LoginFormElements, cred config.Credential, targetURL string) (*BruteForceResult, error) {
+	b.startRecording()
+	result, err := b.tryLogin(elements, cred, targetURL)
+	b.attempts++
+
+	if err == nil && !b.browser.Alive() {
+		return nil, fmt.Errorf("提交后浏览器不可用: %s", b.browser.CrashReason())
+	}
+	return result, err
+}
+
+// restartBrowser 重启浏览器并重新打开登录页面，返回下一次尝试使用的表单元素。
+// 新进程中的后端节点ID重新编号，旧ID可能指向无关节点，重新打开后按与重新加载相同的流程
+// 刷新节点记录并比对表单指纹
+func (b *BruteForceEngine) restartBrowser(targetURL string, elements *detector.LoginFormElements) (*detector.LoginFormElements, error) {
+	b.attempts = 0
+	b.sinceReload = 0
+	if err := b.browser.Restart(); err != nil {
+		return elements, err
+	}
+	if err := b.browser.NavigateTo(targetURL); err != nil {
+		return elements, fmt.Errorf("重启后打开登录页面失败: %v", err)
+	}
+	return b.verifyReloaded(elements), nil
+}
+
 // tryLogin 尝试登录
 func (b *BruteForceEngine) tryLogin(elements *detector.LoginFormElements, cred config.Credential, targetURL string) (*BruteForceResult, error) {
 	b.logger.Debug("🔄 开始清空并填充表单...")
 
+	// 清空上一次尝试的对话框和导航记录
+	b.browser.BeginAttempt()
+
 	// 填充用户名
 	b.logger.Debug(fmt.Sprintf("📝 填充用户名: %s", cred.Username))
-	if err := b.fillFormField(elements.UsernameSelector, cred.Username, "用户名"); err != nil {
+	if err := b.fillFormField(b.locate(elements.UsernameRef, elements.UsernameSelector), cred.Username, "用户名"); err != nil {
 		return nil, fmt.Errorf("填充用户名失败: %v", err)
 	}
 
+	// 分步登录：点击"下一步"并等待密码框出现
+	if elements.MultiStep {
+		if err := b.advancePasswordStep(elements); err != nil {
+			return nil, err
+		}
+	}
+
 	// 填充密码
 	b.logger.Debug(fmt.Sprintf("🔐 填充密码: %s", cred.Password))
-	if err := b.fillFormField(elements.PasswordSelector, cred.Password, "密码"); err != nil {
+	if err := b.fillFormField(b.locate(elements.PasswordRef, elements.PasswordSelector), cred.Password, "密码"); err != nil {
 		return nil, fmt.Errorf("填充密码失败: %v", err)
 	}
 
 	// 如果有复选框，先点击复选框
 	if elements.HasCheckbox && elements.CheckboxSelector != "" {
-		b.logger.Debug(fmt.Sprintf("☑️  点击用户协议复选框: %s", elements.CheckboxSelector))
-		if err := b.browser.ClickCheckbox(elements.CheckboxSelector); err != nil {
+		checkboxSelector := b.locate(elements.CheckboxRef, elements.CheckboxSelector)
+		b.logger.Debug(fmt.Sprintf("☑️  点击用户协议复选框: %s", checkboxSelector))
+		if err := b.browser.ClickCheckbox(checkboxSelector); err != nil {
 			b.logger.Warn(fmt.Sprintf("⚠️  点击复选框失败: %v", err))
 			// 复选框点击失败不一定要中断，有些页面可能不是必须的
 		}
@@ -285,50 +455,135 @@ func (b *BruteForceEngine) tryLogin(elements *detector.LoginFormElements, cred c
 	// 获取提交前的URL
 	beforeURL, _ := b.browser.GetCurrentURL()
 
+	// 清空导航记录，只记录提交后的跳转链
+	b.browser.ResetNavigationLog()
+
 	// 点击提交按钮
-	b.logger.Debug(fmt.Sprintf("🔘 点击提交按钮: %s", elements.SubmitSelector))
-	if err := b.browser.ClickElement(elements.SubmitSelector); err != nil {
+	submitSelector := b.locate(elements.SubmitRef, elements.SubmitSelector)
+	b.logger.Debug(fmt.Sprintf("🔘 点击提交按钮: %s", submitSelector))
+	if err := b.browser.ClickElement(submitSelector); err != nil {
 		return &BruteForceResult{
 			Success:      false,
+			Outcome:      OutcomeError,
 			Username:     cred.Username,
 			Password:     cred.Password,
 			ErrorMessage: fmt.Sprintf("点击提交按钮失败: %v", err),
+			TargetURL:    targetURL,
 			URL:          targetURL,
 		}, fmt.Errorf("点击提交按钮失败: %v", err)
 	}
 
-	// 等待页面响应
-	time.Sleep(3 * time.Second)
+	// 等待页面响应，期间持续采样错误提示（toast等短暂消息）
+	messages := b.sampleErrorMessages(3 * time.Second)
+
+	// 合并JavaScript对话框中的提示
+	messages = append(b.browser.DialogMessages(), messages...)
 
 	// 获取提交后的URL
 	afterURL, _ := b.browser.GetCurrentURL()
 
 	// 检查登录是否成功
-	success := b.checkLoginSuccess(beforeURL, afterURL)
+	outcome := b.checkLoginSuccess(beforeURL, afterURL, messages)
+
+	// 记录提交后的跳转链
+	redirectChain := b.browser.NavigationLog()
+
+	// 检查提交后新打开的标签页
+	var openedTabs []string
+	if len(b.browser.OpenedTabs()) > 0 {
+		tabOutcome, tab, tabURLs := b.checkOpenedTabs(beforeURL, messages, b.loginFormGone(elements))
+		openedTabs = tabURLs
+
+		if tab != nil && outcomeRank(tabOutcome) > outcomeRank(outcome) {
+			b.logger.Debug(fmt.Sprintf("✅ 登录后页面在新标签页中打开: %s", tab.URL))
+			outcome = tabOutcome
+			afterURL = tab.URL
+
+			// 在当前标签页中打开登录后页面，以便采集截图等证据
+			if err := b.browser.NavigateTo(tab.URL); err != nil {
+				b.logger.Debug(fmt.Sprintf("打开新标签页地址失败: %v", err))
+			}
+		}
+
+		// 关闭新标签页，保证后续尝试在干净的状态下进行
+		b.browser.CloseOpenedTabs()
+	}
 
 	return &BruteForceResult{
-		Success:  success,
-		Username: cred.Username,
-		Password: cred.Password,
-		URL:      afterURL,
+		Success:       outcome == OutcomeSuccess || outcome == OutcomeSuspected,
+		Outcome:       outcome,
+		Username:      cred.Username,
+		Password:      cred.Password,
+		TargetURL:     targetURL,
+		URL:           afterURL,
+		Timestamp:     time.Now(),
+		RedirectChain: redirectChain,
+		Messages:      messages,
+		OpenedTabs:    openedTabs,
 	}, nil
 }
 
-// checkLoginSuccess 检查登录是否成功
-func (b *BruteForceEngine) checkLoginSuccess(beforeURL, afterURL string) bool {
+// sampleErrorMessages 在等待页面响应期间周期性采样页面中的错误提示
+func (b *BruteForceEngine) sampleErrorMessages(duration time.Duration) []string {
+	var messages []string
+	seen := make(map[string]bool)
+
+	deadline := time.Now().Add(duration)
+	for {
+		sampled, err := b.browser.CollectErrorMessages(b.config.Bruteforce.ErrorSelectors)
+		if err != nil {
+			b.logger.Debug(fmt.Sprintf("采样错误提示失败: %v", err))
+		}
+		for _, msg := range sampled {
+			if !seen[msg] {
+				seen[msg] = true
+				messages = append(messages, msg)
+				b.logger.Debug(fmt.Sprintf("💬 捕获到页面提示: %s", msg))
+			}
+		}
+
+		if time.Now().Add(300 * time.Millisecond).After(deadline) {
+			break
+		}
+		time.Sleep(300 * time.Millisecond)
+	}
+
+	if remaining := time.Until(deadline); remaining > 0 {
+		time.Sleep(remaining)
+	}
+
+	return messages
+}
+
+// checkLoginSuccess 检查登录是否成功，返回尝试结果类型
+func (b *BruteForceEngine) checkLoginSuccess(beforeURL, afterURL string, messages []string) LoginOutcome {
 	b.logger.Debug(fmt.Sprintf("🔍 检查登录结果: %s -> %s", beforeURL, afterURL))
 
+	pageContent, err := b.browser.GetPageContent()
+	if err != nil {
+		b.logger.Debug(fmt.Sprintf("获取页面内容失败: %v", err))
+		return OutcomeFailure
+	}
+
+	return b.classifyPage(beforeURL, afterURL, pageContent, messages)
+}
+
+// classifyPage 根据提交前后URL、页面内容和提示信息判断尝试结果
+func (b *BruteForceEngine) classifyPage(beforeURL, afterURL, pageContent string, messages []string) LoginOutcome {
+	// 0. 检查对话框和错误提示区域中的失败信息
+	for _, msg := range messages {
+		for _, keyword := range messageFailureKeywords {
+			if contains(msg, keyword) {
+				b.logger.Debug(fmt.Sprintf("❌ 页面提示包含失败关键词 %s: %s", keyword, msg))
+				return OutcomeFailure
+			}
+		}
+	}
+
 	// 1. 检查URL是否发生变化
 	if beforeURL != afterURL {
 		b.logger.Debug("✅ URL发生变化，可能登录成功")
 
-		// 检查是否跳转到成功页面
-		pageContent, err := b.browser.GetPageContent()
-		if err != nil {
-			b.logger.Debug(fmt.Sprintf("获取页面内容失败: %v", err))
-			return false
-		}
-
 		// 检查成功关键词
 		successKeywords := []string{
 			"欢迎", "控制台", "首页", "dashboard", "welcome", "index", "main", "home",
@@ -338,38 +593,80 @@ func (b *BruteForceEngine) checkLoginSuccess(beforeURL, afterURL string) bool {
 		for _, keyword := range successKeywords {
 			if contains(pageContent, keyword) {
 				b.logger.Debug(fmt.Sprintf("✅ 在页面中找到成功关键词: %s", keyword))
-				return true
+				return OutcomeSuccess
 			}
 		}
 	}
 
 	// 2. 检查页面内容中的失败关键词
-	pageContent, err := b.browser.GetPageContent()
-	if err != nil {
-		b.logger.Debug(fmt.Sprintf("获取页面内容失败: %v", err))
-		return false
-	}
-
-	failureKeywords := []string{
-		"密码错误", "用户名错误", "登录失败", "认证失败", "invalid", "error",
-		"incorrect", "failed", "wrong", "验证码", "captcha", "验证失败",
-	}
-
 	for _, keyword := range failureKeywords {
 		if contains(pageContent, keyword) {
 			b.logger.Debug(fmt.Sprintf("❌ 在页面中找到失败关键词: %s", keyword))
-			return false
+			return OutcomeFailure
 		}
 	}
 
 	// 3. 如果URL没有变化，通常表示登录失败
 	if beforeURL == afterURL {
 		b.logger.Debug("❌ URL未发生变化，登录失败")
+		return OutcomeFailure
+	}
+
+	b.logger.Debug("✅ 未找到明确的失败标识，判定为疑似成功")
+	return OutcomeSuspected
+}
+
+// checkOpenedTabs 检查提交后新打开的标签页（登录后页面可能在新窗口中打开），返回其中最好的结果。
+// 标签页地址总与提交前不同，帮助、广告、协议等弹窗也会被判为疑似成功，
+// 因此只有命中成功关键词，或主标签页的登录表单已经消失时，才采用失败以外的结果
+func (b *BruteForceEngine) checkOpenedTabs(beforeURL string, messages []string, formGone bool) (LoginOutcome, *browser.TabInfo, []string) {
+	best := OutcomeFailure
+	var bestTab *browser.TabInfo
+	var tabURLs []string
+
+	for _, id := range b.browser.OpenedTabs() {
+		info, err := b.browser.InspectTab(id)
+		if err != nil {
+			b.logger.Debug(fmt.Sprintf("读取新标签页失败: %v", err))
+			continue
+		}
+		tabURLs = append(tabURLs, info.URL)
+		b.logger.Debug(fmt.Sprintf("🪟 检查新标签页: %s", info.URL))
+
+		outcome := b.classifyPage(beforeURL, info.URL, info.Content, messages)
+		if outcome == OutcomeSuspected && !formGone {
+			b.logger.Debug(fmt.Sprintf("❌ 新标签页没有成功标识且登录表单仍在，视为无关弹窗: %s", info.URL))
+			outcome = OutcomeFailure
+		}
+		if outcomeRank(outcome) > outcomeRank(best) {
+			best = outcome
+			bestTab = info
+		}
+	}
+
+	return best, bestTab, tabURLs
+}
+
+// loginFormGone 提交后主标签页中的密码输入框是否已不可见
+func (b *BruteForceEngine) loginFormGone(elements *detector.LoginFormElements) bool {
+	selector := b.locate(elements.PasswordRef, elements.PasswordSelector)
+	if selector == "" {
 		return false
 	}
+	ref, err := b.browser.FindVisibleElement([]string{selector})
+	return err == nil && ref == nil
+}
 
-	b.logger.Debug("✅ 未找到明确的失败标识，判定为成功")
-	return true
+// outcomeRank 结果优先级：成功 > 疑似成功 > 失败
+func outcomeRank(outcome LoginOutcome) int {
+	switch outcome {
+	case OutcomeSuccess:
+		return 2
+	case OutcomeSuspected:
+		return 1
+	default:
+		return 0
+	}
 }
 
 // contains 检查字符串是否包含子字符串（忽略大小写）
@@ -407,6 +704,22 @@ func indexOf(text, substr string) int {
 	return -1
 }
 
+// stripFragment 去掉地址中的#片段
+func stripFragment(url string) string {
+	if i := strings.Index(url, "#"); i >= 0 {
+		return url[:i]
+	}
+	return url
+}
+
+// locate 返回字段当前的精确选择器，没有节点记录时使用检测到的选择器
+func (b *BruteForceEngine) locate(ref *browser.ElementRef, selector string) string {
+	if ref == nil {
+		return selector
+	}
+	return b.browser.Locate(ref)
+}
+
 // fillFormField 改进的表单字段填充方法
 func (b *BruteForceEngine) fillFormField(selector, value, fieldName string) error {
 	b.logger.Debug(fmt.Sprintf("🖊️  开始填充%s字段: %s", fieldName, selector))
@@ -0,0 +1,342 @@
+package bruteforce
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/chromedp/cdproto/network"
+
+	"github.com/cyberspacesec/chrome_auto_login/pkg/browser"
+)
+
+// unsafeFilenameChars 文件名中需要替换的字符
+var unsafeFilenameChars = regexp.MustCompile(`[^\p{L}\p{N}._-]+`)
+
+// shouldCaptureScreenshot 根据配置判断该结果是否需要截图
+func (b *BruteForceEngine) shouldCaptureScreenshot(outcome LoginOutcome) bool {
+	cfg := b.config.Results.Screenshot
+	if !cfg.Enabled {
+		return false
+	}
+
+	switch outcome {
+	case OutcomeSuccess:
+		return cfg.OnSuccess
+	case OutcomeSuspected:
+		return cfg.OnSuspected
+	case OutcomeFailure:
+		return cfg.OnFailure
+	case OutcomeError:
+		return cfg.OnError
+	default:
+		return false
+	}
+}
+
+// captureEvidence 按配置保存本次尝试的截图和证据包
+func (b *BruteForceEngine) captureEvidence(result *BruteForceResult) {
+	b.captureScreenshot(result)
+	b.saveEvidenceBundle(result)
+}
+
+// captureScreenshot 按配置为本次尝试截图并保存到结果目录
+func (b *BruteForceEngine) captureScreenshot(result *BruteForceResult) {
+	if !b.shouldCaptureScreenshot(result.Outcome) {
+		return
+	}
+
+	cfg := b.config.Results.Screenshot
+
+	var (
+		buf []byte
+		err error
+	)
+	if cfg.FullPage {
+		buf, err = b.browser.FullScreenshot(cfg.Quality)
+	} else {
+		buf, err = b.browser.Screenshot()
+	}
+	if err != nil {
+		b.logger.Warn(fmt.Sprintf("⚠️  截图失败: %v", err))
+		return
+	}
+	result.Screenshot = buf
+
+	ext := ".png"
+	if cfg.FullPage && cfg.Quality > 0 && cfg.Quality < 100 {
+		ext = ".jpg"
+	}
+
+	dir := filepath.Join(b.resultLogger.SaveDir(), cfg.Dir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		b.logger.Warn(fmt.Sprintf("⚠️  创建截图目录失败: %v", err))
+		return
+	}
+
+	path := filepath.Join(dir, evidenceBaseName(result.TargetURL, result.Username, result.Timestamp)+ext)
+	if err := os.WriteFile(path, buf, 0644); err != nil {
+		b.logger.Warn(fmt.Sprintf("⚠️  保存截图失败: %v", err))
+		return
+	}
+
+	result.ScreenshotPath = path
+	b.logger.Debug(fmt.Sprintf("📸 截图已保存: %s", path))
+}
+
+// evidenceArtifact 证据包中的单个文件
+type evidenceArtifact struct {
+	Name   string `json:"name"`
+	Size   int    `json:"size"`
+	SHA256 string `json:"sha256"`
+	data   []byte
+}
+
+// evidenceManifest 证据包清单
+type evidenceManifest struct {
+	TargetURL     string                  `json:"target_url"`
+	FinalURL      string                  `json:"final_url"`
+	Title         string                  `json:"title"`
+	Username      string                  `json:"username"`
+	Outcome       LoginOutcome            `json:"outcome"`
+	RedirectChain []browser.NavigationHop `json:"redirect_chain"`
+	CookiesMasked bool                    `json:"cookies_masked"`
+	CreatedAt     time.Time               `json:"created_at"`
+	Artifacts     []evidenceArtifact      `json:"artifacts"`
+}
+
+// saveEvidenceBundle 为成功结果生成包含MHTML快照、Cookie等信息的证据包
+func (b *BruteForceEngine) saveEvidenceBundle(result *BruteForceResult) {
+	cfg := b.config.Results.Evidence
+	if !cfg.Enabled {
+		return
+	}
+	if result.Outcome != OutcomeSuccess && !(result.Outcome == OutcomeSuspected && cfg.OnSuspected) {
+		return
+	}
+
+	title, finalURL, _, err := b.browser.GetPageInfo()
+	if err != nil {
+		b.logger.Warn(fmt.Sprintf("⚠️  获取页面信息失败: %v", err))
+		finalURL = result.URL
+	}
+	result.PageTitle = title
+
+	manifest := &evidenceManifest{
+		TargetURL:     result.TargetURL,
+		FinalURL:      finalURL,
+		Title:         title,
+		Username:      result.Username,
+		Outcome:       result.Outcome,
+		RedirectChain: result.RedirectChain,
+		CookiesMasked: cfg.MaskCookies,
+		CreatedAt:     time.Now(),
+	}
+
+	var artifacts []evidenceArtifact
+	addArtifact := func(name string, data []byte) {
+		sum := sha256.Sum256(data)
+		artifacts = append(artifacts, evidenceArtifact{
+			Name:   name,
+			Size:   len(data),
+			SHA256: hex.EncodeToString(sum[:]),
+			data:   data,
+		})
+	}
+
+	// MHTML快照
+	if snapshot, err := b.browser.CaptureMHTML(); err == nil {
+		addArtifact("snapshot.mhtml", []byte(snapshot))
+	} else {
+		b.logger.Warn(fmt.Sprintf("⚠️  获取MHTML快照失败: %v", err))
+	}
+
+	// 截图
+	screenshot := result.Screenshot
+	if len(screenshot) == 0 {
+		screenshot, _ = b.browser.FullScreenshot(100)
+	}
+	if len(screenshot) > 0 {
+		ext := filepath.Ext(result.ScreenshotPath)
+		if ext == "" {
+			ext = ".png"
+		}
+		addArtifact("screenshot"+ext, screenshot)
+	}
+
+	// 录屏
+	if filepath.Ext(result.RecordingPath) == ".gif" {
+		if data, err := os.ReadFile(result.RecordingPath); err == nil {
+			addArtifact("recording.gif", data)
+		}
+	}
+
+	// Cookie
+	if cookies, err := b.browser.GetCookies(); err == nil {
+		if cfg.MaskCookies {
+			cookies = maskCookies(cookies)
+		}
+		if data, err := json.MarshalIndent(cookies, "", "  "); err == nil {
+			addArtifact("cookies.json", data)
+		}
+	} else {
+		b.logger.Warn(fmt.Sprintf("⚠️  获取Cookie失败: %v", err))
+	}
+
+	// 页面信息
+	pageInfo := map[string]interface{}{
+		"final_url":      finalURL,
+		"title":          title,
+		"redirect_chain": result.RedirectChain,
+	}
+	if data, err := json.MarshalIndent(pageInfo, "", "  "); err == nil {
+		addArtifact("page.json", data)
+	}
+
+	manifest.Artifacts = artifacts
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		b.logger.Warn(fmt.Sprintf("⚠️  生成证据清单失败: %v", err))
+		return
+	}
+
+	dir := filepath.Join(b.resultLogger.SaveDir(), cfg.Dir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		b.logger.Warn(fmt.Sprintf("⚠️  创建证据目录失败: %v", err))
+		return
+	}
+	base := filepath.Join(dir, evidenceBaseName(result.TargetURL, result.Username, result.Timestamp))
+
+	var path string
+	if cfg.Format == "zip" {
+		path, err = writeEvidenceZip(base+".zip", artifacts, manifestData)
+	} else {
+		path, err = writeEvidenceDir(base, artifacts, manifestData)
+	}
+	if err != nil {
+		b.logger.Warn(fmt.Sprintf("⚠️  保存证据包失败: %v", err))
+		return
+	}
+
+	result.EvidencePath = path
+	b.logger.Info(fmt.Sprintf("🗂️  证据包已保存: %s", path))
+}
+
+// writeEvidenceDir 以目录形式保存证据包
+func writeEvidenceDir(dir string, artifacts []evidenceArtifact, manifest []byte) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	for _, artifact := range artifacts {
+		if err := os.WriteFile(filepath.Join(dir, artifact.Name), artifact.data, 0644); err != nil {
+			return "", err
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "manifest.json"), manifest, 0644); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// writeEvidenceZip 以zip压缩包形式保存证据包
+func writeEvidenceZip(path string, artifacts []evidenceArtifact, manifest []byte) (string, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+
+	err = writeZipEntries(file, artifacts, manifest)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		// 不保留写了一半的证据包
+		os.Remove(path)
+		return "", err
+	}
+	return path, nil
+}
+
+// writeZipEntries 写入证据文件和清单
+func writeZipEntries(out io.Writer, artifacts []evidenceArtifact, manifest []byte) error {
+	zw := zip.NewWriter(out)
+	for _, artifact := range artifacts {
+		w, err := zw.Create(artifact.Name)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(artifact.data); err != nil {
+			return err
+		}
+	}
+	w, err := zw.Create("manifest.json")
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(manifest); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// maskCookies 遮蔽Cookie值，仅保留前4个字符
+func maskCookies(cookies []*network.Cookie) []*network.Cookie {
+	masked := make([]*network.Cookie, 0, len(cookies))
+	for _, cookie := range cookies {
+		c := *cookie
+		c.Value = maskValue(c.Value)
+		masked = append(masked, &c)
+	}
+	return masked
+}
+
+// maskValue 遮蔽敏感值
+func maskValue(value string) string {
+	runes := []rune(value)
+	if len(runes) <= 4 {
+		return "****"
+	}
+	return string(runes[:4]) + "****"
+}
+
+// recordResult 写入结构化结果记录
+func (b *BruteForceEngine) recordResult(result *BruteForceResult) {
+	if err := b.resultLogger.LogRecord(result); err != nil {
+		b.logger.Warn(fmt.Sprintf("⚠️  写入结果记录失败: %v", err))
+	}
+}
+
+// evidenceBaseName 生成确定性的证据文件名: 主机_用户名_时间戳，时间戳精确到毫秒，
+// 同一秒内多次尝试同一用户名（如崩溃后重试）时不会互相覆盖
+func evidenceBaseName(targetURL, username string, ts time.Time) string {
+	host := targetURL
+	if u, err := url.Parse(targetURL); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+
+	return fmt.Sprintf("%s_%s_%s",
+		sanitizeFilename(host),
+		sanitizeFilename(username),
+		ts.Format("20060102-150405.000"),
+	)
+}
+
+// sanitizeFilename 替换文件名中的非法字符
+func sanitizeFilename(s string) string {
+	s = unsafeFilenameChars.ReplaceAllString(s, "_")
+	if s == "" {
+		return "_"
+	}
+	return s
+}
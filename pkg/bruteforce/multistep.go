@@ -0,0 +1,24 @@
+package bruteforce
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cyberspacesec/chrome_auto_login/pkg/detector"
+)
+
+// advancePasswordStep 分步登录中点击"下一步"，等待密码框出现。
+// 首次会识别并记录第二步的密码框和提交按钮，之后的尝试直接使用记录的选择器
+func (b *BruteForceEngine) advancePasswordStep(elements *detector.LoginFormElements) error {
+	nextSelector := b.locate(elements.NextRef, elements.NextSelector)
+	b.logger.Debug(fmt.Sprintf("➡️  点击下一步: %s", nextSelector))
+	if err := b.browser.ClickElement(nextSelector); err != nil {
+		return fmt.Errorf("点击下一步失败: %v", err)
+	}
+
+	timeout := time.Duration(b.config.GetStepTimeout()) * time.Second
+	if err := b.detector.WaitPasswordStep(elements, timeout); err != nil {
+		return fmt.Errorf("等待密码输入页面失败: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package bruteforce
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cyberspacesec/chrome_auto_login/pkg/browser"
+)
+
+// startRecording 按配置开始录制本次尝试
+func (b *BruteForceEngine) startRecording() {
+	cfg := b.config.Results.Recording
+	if !cfg.Enabled {
+		return
+	}
+	if err := b.browser.StartScreencast(cfg.Quality, cfg.MaxWidth, cfg.MaxHeight, cfg.EveryNthFrame); err != nil {
+		b.logger.Warn(fmt.Sprintf("⚠️  开始录屏失败: %v", err))
+	}
+}
+
+// saveRecording 停止录制并将录屏保存到结果目录
+func (b *BruteForceEngine) saveRecording(result *BruteForceResult) {
+	cfg := b.config.Results.Recording
+	if !cfg.Enabled {
+		return
+	}
+
+	frames := b.browser.StopScreencast()
+	if len(frames) == 0 {
+		return
+	}
+
+	dir := filepath.Join(b.resultLogger.SaveDir(), cfg.Dir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		b.logger.Warn(fmt.Sprintf("⚠️  创建录屏目录失败: %v", err))
+		return
+	}
+	base := filepath.Join(dir, evidenceBaseName(result.TargetURL, result.Username, result.Timestamp))
+
+	var (
+		path string
+		err  error
+	)
+	if cfg.Format == "png" {
+		path, err = writeFrameSequence(base, frames)
+	} else {
+		path, err = writeFramesGIF(base+".gif", frames)
+	}
+	if err != nil {
+		b.logger.Warn(fmt.Sprintf("⚠️  保存录屏失败: %v", err))
+		return
+	}
+
+	result.RecordingPath = path
+	b.logger.Debug(fmt.Sprintf("🎬 录屏已保存: %s (%d帧)", path, len(frames)))
+}
+
+// decodedFrame 解码成功的录屏帧
+type decodedFrame struct {
+	img       image.Image
+	timestamp time.Time
+}
+
+// decodeFrames 解码录屏帧，跳过无法解码的帧
+func decodeFrames(frames []browser.ScreencastFrame) []decodedFrame {
+	var decoded []decodedFrame
+	for _, frame := range frames {
+		img, _, err := image.Decode(bytes.NewReader(frame.Data))
+		if err != nil {
+			continue
+		}
+		decoded = append(decoded, decodedFrame{img: img, timestamp: frame.Timestamp})
+	}
+	return decoded
+}
+
+// writeFramesGIF 将录屏帧合成为GIF动图，帧间隔取自录制时间戳
+func writeFramesGIF(path string, frames []browser.ScreencastFrame) (string, error) {
+	decoded := decodeFrames(frames)
+	if len(decoded) == 0 {
+		return "", fmt.Errorf("没有可用的录屏帧")
+	}
+
+	anim := &gif.GIF{}
+	for i, frame := range decoded {
+		bounds := frame.img.Bounds()
+		paletted := image.NewPaletted(bounds, palette.Plan9)
+		draw.FloydSteinberg.Draw(paletted, bounds, frame.img, bounds.Min)
+
+		// GIF帧间隔单位为1/100秒，按保留下来的下一帧计算，最后一帧停留1秒
+		delay := 100
+		if i+1 < len(decoded) {
+			delay = int(decoded[i+1].timestamp.Sub(frame.timestamp) / (10 * time.Millisecond))
+		}
+		if delay < 2 {
+			delay = 2
+		}
+
+		anim.Image = append(anim.Image, paletted)
+		anim.Delay = append(anim.Delay, delay)
+		if bounds.Dx() > anim.Config.Width {
+			anim.Config.Width = bounds.Dx()
+		}
+		if bounds.Dy() > anim.Config.Height {
+			anim.Config.Height = bounds.Dy()
+		}
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+
+	err = gif.EncodeAll(file, anim)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		// 不保留写了一半的录屏
+		os.Remove(path)
+		return "", err
+	}
+	return path, nil
+}
+
+// writeFrameSequence 将录屏帧保存为按序编号的PNG文件，并记录各帧的时间偏移
+func writeFrameSequence(dir string, frames []browser.ScreencastFrame) (string, error) {
+	decoded := decodeFrames(frames)
+	if len(decoded) == 0 {
+		return "", fmt.Errorf("没有可用的录屏帧")
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	if err := writeFrameFiles(dir, decoded); err != nil {
+		// 不保留写了一半的录屏
+		os.RemoveAll(dir)
+		return "", err
+	}
+	return dir, nil
+}
+
+// writeFrameFiles 写入PNG帧和帧索引，时间偏移相对于第一个保留下来的帧
+func writeFrameFiles(dir string, frames []decodedFrame) error {
+	type frameIndex struct {
+		File     string `json:"file"`
+		OffsetMs int64  `json:"offset_ms"`
+	}
+	var index []frameIndex
+
+	start := frames[0].timestamp
+	for i, frame := range frames {
+		name := fmt.Sprintf("frame_%04d.png", i+1)
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, frame.img); err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), buf.Bytes(), 0644); err != nil {
+			return err
+		}
+		index = append(index, frameIndex{File: name, OffsetMs: frame.timestamp.Sub(start).Milliseconds()})
+	}
+
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "frames.json"), data, 0644)
+}
@@ -0,0 +1,118 @@
+package bruteforce
+
+import (
+	"fmt"
+
+	"github.com/cyberspacesec/chrome_auto_login/pkg/browser"
+	"github.com/cyberspacesec/chrome_auto_login/pkg/config"
+	"github.com/cyberspacesec/chrome_auto_login/pkg/detector"
+)
+
+// rememberForm 记录登录表单指纹，作为后续判断页面是否失效的基准
+func (b *BruteForceEngine) rememberForm(elements *detector.LoginFormElements) {
+	state, err := b.inspectForm(elements)
+	if err != nil {
+		b.logger.Debug(fmt.Sprintf("记录表单指纹失败: %v", err))
+		b.fingerprint = ""
+		return
+	}
+	b.fingerprint = state.Fingerprint
+}
+
+// prepareAttempt 按重置策略在两次尝试之间重置登录页面，返回下一次尝试使用的表单元素
+func (b *BruteForceEngine) prepareAttempt(targetURL string, elements *detector.LoginFormElements) *detector.LoginFormElements {
+	strategy, every := b.config.GetResetStrategy(targetURL)
+
+	reason := ""
+	currentURL, _ := b.browser.GetCurrentURL()
+	switch {
+	case currentURL != targetURL:
+		reason = "页面地址已变化"
+	case elements.MultiStep:
+		reason = "分步登录需要回到第一步"
+	case strategy == config.ResetReload:
+		reason = "重置策略为每次重新加载"
+	case strategy == config.ResetReloadEveryN && every > 0 && b.sinceReload >= every:
+		reason = fmt.Sprintf("已连续 %d 次尝试未重新加载", b.sinceReload)
+	}
+
+	// 原地重置，并检测页面是否已失效
+	if reason == "" {
+		fields := []string{b.locate(elements.UsernameRef, elements.UsernameSelector), b.locate(elements.PasswordRef, elements.PasswordSelector)}
+		if elements.CaptchaSelector != "" {
+			fields = append(fields, b.locate(elements.CaptchaRef, elements.CaptchaSelector))
+		}
+		if err := b.browser.ResetForm(fields, b.config.Bruteforce.ErrorSelectors); err != nil {
+			reason = "原地重置失败"
+		} else if state, err := b.inspectForm(elements); err != nil {
+			reason = "无法检查登录表单"
+		} else if !state.FieldsPresent {
+			reason = "输入框已消失"
+		} else if !state.SubmitReady {
+			reason = "提交按钮已消失或被禁用"
+		} else if b.fingerprint != "" && state.Fingerprint != b.fingerprint {
+			reason = "表单结构已变化"
+		}
+	}
+
+	if reason == "" {
+		b.sinceReload++
+		return elements
+	}
+
+	b.logger.Debug(fmt.Sprintf("🔄 重新加载登录页面: %s", reason))
+	if err := b.browser.NavigateTo(targetURL); err != nil {
+		b.logger.Debug(fmt.Sprintf("重新导航到登录页面失败: %v", err))
+		return elements
+	}
+	b.sinceReload = 1
+	return b.verifyReloaded(elements)
+}
+
+// verifyReloaded 登录页面重新打开后刷新表单元素的节点记录，表单结构已变化时重新识别表单元素
+func (b *BruteForceEngine) verifyReloaded(elements *detector.LoginFormElements) *detector.LoginFormElements {
+	// 旧的节点ID已失效，先刷新再检查表单；分步登录的第一步没有密码框，不做表单结构比对
+	b.refreshRefs(elements)
+	if elements.MultiStep {
+		return elements
+	}
+
+	// 重新加载后表单结构仍不一致时重新识别表单元素
+	state, err := b.inspectForm(elements)
+	if err == nil && state.Usable() && (b.fingerprint == "" || state.Fingerprint == b.fingerprint) {
+		return elements
+	}
+
+	b.logger.Info("🔍 登录表单已变化，重新识别表单元素")
+	detected, err := b.detector.DetectLoginForm()
+	if err != nil || detected.UsernameSelector == "" || detected.PasswordSelector == "" || detected.SubmitSelector == "" {
+		b.logger.Warn("⚠️  重新识别登录表单失败，继续使用原有表单元素")
+		return elements
+	}
+	b.rememberForm(detected)
+	return detected
+}
+
+// inspectForm 按节点记录定位表单元素并检查登录表单状态
+func (b *BruteForceEngine) inspectForm(elements *detector.LoginFormElements) (*browser.FormState, error) {
+	return b.browser.InspectForm(
+		b.locate(elements.UsernameRef, elements.UsernameSelector),
+		b.locate(elements.PasswordRef, elements.PasswordSelector),
+		b.locate(elements.SubmitRef, elements.SubmitSelector),
+	)
+}
+
+// refreshRefs 页面重新加载后后端节点ID失效，按唯一选择器重新记录节点
+func (b *BruteForceEngine) refreshRefs(elements *detector.LoginFormElements) {
+	refs := []*browser.ElementRef{elements.UsernameRef, elements.PasswordRef, elements.CaptchaRef, elements.SubmitRef, elements.CheckboxRef, elements.NextRef}
+	for _, ref := range refs {
+		if ref == nil {
+			continue
+		}
+		if fresh, err := b.browser.ResolveElement(ref.Selector); err == nil {
+			ref.BackendNodeID = fresh.BackendNodeID
+		} else {
+			ref.BackendNodeID = 0
+		}
+	}
+}
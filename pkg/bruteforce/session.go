@@ -0,0 +1,75 @@
+package bruteforce
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/cyberspacesec/chrome_auto_login/pkg/browser"
+)
+
+// captureSession 采集登录成功后的会话状态，供移交和导出使用
+func (b *BruteForceEngine) captureSession(result *BruteForceResult) {
+	if !result.Success {
+		return
+	}
+
+	state, err := b.browser.CaptureSession()
+	if err != nil {
+		b.logger.Warn(fmt.Sprintf("⚠️  %v", err))
+		return
+	}
+	result.Session = state
+}
+
+// exportSession 将登录成功后的会话导出为常用格式，便于curl、sqlmap、Burp等工具复用
+func (b *BruteForceEngine) exportSession(result *BruteForceResult) {
+	cfg := b.config.Results.SessionExport
+	if !cfg.Enabled || result.Session == nil {
+		return
+	}
+	state := result.Session
+
+	dir := filepath.Join(b.resultLogger.SaveDir(), cfg.Dir, evidenceBaseName(result.TargetURL, result.Username, result.Timestamp))
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		b.logger.Warn(fmt.Sprintf("⚠️  创建会话导出目录失败: %v", err))
+		return
+	}
+
+	cookiesJSON, err := json.MarshalIndent(state.Cookies, "", "  ")
+	if err != nil {
+		b.logger.Warn(fmt.Sprintf("⚠️  导出Cookie失败: %v", err))
+		return
+	}
+	storageJSON, err := json.MarshalIndent(map[string]interface{}{
+		"url":             state.URL,
+		"origin":          state.Origin,
+		"local_storage":   state.LocalStorage,
+		"session_storage": state.SessionStorage,
+	}, "", "  ")
+	if err != nil {
+		b.logger.Warn(fmt.Sprintf("⚠️  导出Storage失败: %v", err))
+		return
+	}
+
+	header := browser.CookieHeader(state.Cookies, state.URL)
+	curl := fmt.Sprintf("Cookie: %s\n\n%s\n", header, browser.CurlCommand(state.Cookies, state.URL))
+
+	files := map[string][]byte{
+		"cookies.json": cookiesJSON,
+		"cookies.txt":  []byte(browser.NetscapeCookies(state.Cookies)),
+		"curl.txt":     []byte(curl),
+		"storage.json": storageJSON,
+	}
+	for name, data := range files {
+		// 会话文件包含有效凭据，仅允许当前用户读取
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0600); err != nil {
+			b.logger.Warn(fmt.Sprintf("⚠️  写入%s失败: %v", name, err))
+			return
+		}
+	}
+
+	result.SessionPath = dir
+	b.logger.Info(fmt.Sprintf("🍪 会话已导出: %s", dir))
+}
@@ -1,9 +1,12 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -17,23 +20,183 @@ type Config struct {
 	Logging            LoggingConfig            `yaml:"logging"`
 	Results            ResultsConfig            `yaml:"results"`
 	Captcha            CaptchaConfig            `yaml:"captcha"`
+	Emulation          EmulationConfig          `yaml:"emulation"`
+	Targets            []TargetConfig           `yaml:"targets"`
 }
 
 // BrowserConfig 浏览器配置
 type BrowserConfig struct {
-	Headless   bool   `yaml:"headless"`
-	Timeout    int    `yaml:"timeout"`
-	Width      int    `yaml:"width"`
-	Height     int    `yaml:"height"`
-	ChromePath string `yaml:"chrome_path"` // Chrome浏览器可执行文件路径（可选）
+	Headless   bool         `yaml:"headless"`
+	Timeout    int          `yaml:"timeout"`
+	Width      int          `yaml:"width"`
+	Height     int          `yaml:"height"`
+	ChromePath string       `yaml:"chrome_path"` // Chrome浏览器可执行文件路径（可选）
+	Launch     LaunchConfig `yaml:"launch"`
+
+	RecycleEveryNAttempts int  `yaml:"recycle_every_n_attempts"` // 每N次登录尝试重启一次浏览器，0表示不重启
+	MaxCrashRestarts      *int `yaml:"max_crash_restarts"`       // 同一组凭据因浏览器崩溃重启的最大次数，0表示不重启
+
+	Blocking BlockingConfig `yaml:"blocking"`
+}
+
+// BlockingConfig 静态资源拦截配置
+type BlockingConfig struct {
+	Enabled       bool     `yaml:"enabled"`
+	ResourceTypes []string `yaml:"resource_types"` // 拦截的资源类型，如 image、media、font
+	URLPatterns   []string `yaml:"url_patterns"`   // 拦截的URL通配符规则（* 任意字符，? 单个字符）
+	AllowPatterns []string `yaml:"allow_patterns"` // 白名单URL通配符规则，优先于拦截规则（如验证码图片）
+}
+
+// LaunchConfig Chrome启动参数配置
+type LaunchConfig struct {
+	Strict      bool     `yaml:"strict"`        // 严格模式：保留沙箱和Web安全策略，不忽略证书错误
+	AddFlags    []string `yaml:"add_flags"`     // 追加的启动参数，如 "proxy-server=http://127.0.0.1:8080"
+	RemoveFlags []string `yaml:"remove_flags"`  // 需要移除的启动参数名称，如 "no-sandbox"
+	UserDataDir string   `yaml:"user_data_dir"` // 用户数据目录: 空或"temp"为临时目录，其他值为持久化目录路径
+}
+
+// EmulationConfig 设备与区域模拟配置
+type EmulationConfig struct {
+	DefaultProfile string                      `yaml:"default_profile"` // 默认使用的模拟配置档，空表示不模拟
+	Profiles       map[string]EmulationProfile `yaml:"profiles"`
+}
+
+// EmulationProfile 模拟配置档（通过CDP Emulation域应用）
+type EmulationProfile struct {
+	UserAgent         string             `yaml:"user_agent"`
+	Platform          string             `yaml:"platform"`
+	Width             int                `yaml:"width"`
+	Height            int                `yaml:"height"`
+	DeviceScaleFactor float64            `yaml:"device_scale_factor"`
+	Mobile            bool               `yaml:"mobile"`
+	Touch             bool               `yaml:"touch"`
+	AcceptLanguage    string             `yaml:"accept_language"`
+	Locale            string             `yaml:"locale"`
+	Timezone          string             `yaml:"timezone"`
+	Geolocation       *GeolocationConfig `yaml:"geolocation"`
+}
+
+// GeolocationConfig 地理位置模拟配置
+type GeolocationConfig struct {
+	Latitude  float64 `yaml:"latitude"`
+	Longitude float64 `yaml:"longitude"`
+	Accuracy  float64 `yaml:"accuracy"`
+}
+
+// TargetConfig 针对特定目标的配置
+type TargetConfig struct {
+	Match       string            `yaml:"match"`   // 匹配目标URL的正则表达式
+	Profile     string            `yaml:"profile"` // 该目标使用的模拟配置档
+	Resolve     map[string]string `yaml:"resolve"` // 主机名解析覆盖（主机名 -> IP），用于测试指定后端
+	Reset       string            `yaml:"reset"`   // 页面重置策略，覆盖bruteforce.reset_strategy
+	ResetEveryN int               `yaml:"reset_every_n"`
 }
 
 // LoginPageDetectionConfig 登录页面检测配置
 type LoginPageDetectionConfig struct {
-	TitlePatterns   []string `yaml:"title_patterns"`
-	URLPatterns     []string `yaml:"url_patterns"`
-	ContentKeywords []string `yaml:"content_keywords"`
-	TitleKeywords   []string `yaml:"title_keywords"`
+	Threshold       float64          `yaml:"threshold"`     // 置信度阈值，达到即判定为登录页面
+	Weights         DetectionWeights `yaml:"weights"`       // 各类特征在置信度中的权重
+	DefaultScore    float64          `yaml:"default_score"` // 未指定分值的关键词/规则使用的分值
+	FormScores      FormScores       `yaml:"form_scores"`   // 表单元素特征分值
+	TitlePatterns   []string         `yaml:"title_patterns"`
+	URLPatterns     []ScoredRule     `yaml:"url_patterns"`
+	ContentKeywords []ScoredRule     `yaml:"content_keywords"`
+	TitleKeywords   []ScoredRule     `yaml:"title_keywords"`
+	RenderWait      RenderWaitConfig `yaml:"render_wait"` // 单页应用客户端渲染等待
+}
+
+// RenderWaitConfig 单页应用客户端渲染等待配置
+type RenderWaitConfig struct {
+	Enabled    bool     `yaml:"enabled"`
+	QuietMs    int      `yaml:"quiet_ms"`    // DOM连续无变化达到该毫秒数视为渲染完成
+	MaxWait    int      `yaml:"max_wait"`    // 最长等待秒数
+	HashWait   int      `yaml:"hash_wait"`   // 判定为非登录页面后等待哈希路由跳转的秒数
+	HashRoutes []string `yaml:"hash_routes"` // 哈希路由应用中放弃前依次尝试的登录路由
+}
+
+// Quiet 获取DOM静默期，未配置时为500毫秒
+func (r *RenderWaitConfig) Quiet() time.Duration {
+	if r.QuietMs <= 0 {
+		return 500 * time.Millisecond
+	}
+	return time.Duration(r.QuietMs) * time.Millisecond
+}
+
+// Ceiling 获取渲染等待的最长时间，未配置时为10秒
+func (r *RenderWaitConfig) Ceiling() time.Duration {
+	if r.MaxWait <= 0 {
+		return 10 * time.Second
+	}
+	return time.Duration(r.MaxWait) * time.Second
+}
+
+// DetectionWeights 登录页面置信度中各类特征的权重
+type DetectionWeights struct {
+	Title   float64 `yaml:"title"`
+	URL     float64 `yaml:"url"`
+	Content float64 `yaml:"content"`
+	Form    float64 `yaml:"form"`
+}
+
+// FormScores 表单元素特征分值
+type FormScores struct {
+	Username float64 `yaml:"username"`
+	Password float64 `yaml:"password"`
+	Submit   float64 `yaml:"submit"`
+}
+
+// ScoredRule 带分值的关键词或正则规则，YAML中可写为字符串或 {pattern: ..., score: ...} 映射
+type ScoredRule struct {
+	Pattern string  `yaml:"pattern"`
+	Score   float64 `yaml:"score"` // 命中时的分值，可为负数（如注册页面关键词）
+}
+
+// UnmarshalYAML 支持字符串和映射两种写法
+func (r *ScoredRule) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		r.Pattern = value.Value
+		r.Score = 0
+		return nil
+	}
+
+	type plain ScoredRule
+	return value.Decode((*plain)(r))
+}
+
+// RuleScore 获取规则命中时的分值，未指定时使用default_score（未配置时为0.2）
+func (d *LoginPageDetectionConfig) RuleScore(rule ScoredRule) float64 {
+	if rule.Score != 0 {
+		return rule.Score
+	}
+	if d.DefaultScore == 0 {
+		return 0.2
+	}
+	return d.DefaultScore
+}
+
+// SignalWeights 获取各类特征的权重，未配置weights的旧配置文件使用默认权重
+func (d *LoginPageDetectionConfig) SignalWeights() DetectionWeights {
+	if d.Weights == (DetectionWeights{}) {
+		return DetectionWeights{Title: 0.3, URL: 0.2, Content: 0.25, Form: 0.25}
+	}
+	return d.Weights
+}
+
+// ElementScores 获取表单元素特征分值，未配置form_scores的旧配置文件使用默认分值
+func (d *LoginPageDetectionConfig) ElementScores() FormScores {
+	if d.FormScores == (FormScores{}) {
+		return FormScores{Username: 0.4, Password: 0.4, Submit: 0.2}
+	}
+	return d.FormScores
+}
+
+// LoginThreshold 获取登录页面置信度阈值
+func (d *LoginPageDetectionConfig) LoginThreshold() float64 {
+	// 未配置阈值时所有页面都会被判定为登录页面，使用保守的默认值
+	if d.Threshold <= 0 {
+		return 0.6
+	}
+	return d.Threshold
 }
 
 // FormElementsConfig 表单元素配置
@@ -43,17 +206,30 @@ type FormElementsConfig struct {
 	CaptchaSelectors  []string `yaml:"captcha_selectors"`
 	SubmitSelectors   []string `yaml:"submit_selectors"`
 	CheckboxSelectors []string `yaml:"checkbox_selectors"`
+	NextSelectors     []string `yaml:"next_selectors"` // 分步登录中"下一步"按钮的识别规则
 }
 
 // BruteforceConfig 爆破配置
 type BruteforceConfig struct {
-	Usernames  []string `yaml:"usernames"`
-	Passwords  []string `yaml:"passwords"`
-	Delay      int      `yaml:"delay"`
-	MaxRetries int      `yaml:"max_retries"`
-	Concurrent int      `yaml:"concurrent"`
+	Usernames      []string `yaml:"usernames"`
+	Passwords      []string `yaml:"passwords"`
+	Delay          int      `yaml:"delay"`
+	MaxRetries     int      `yaml:"max_retries"`
+	Concurrent     int      `yaml:"concurrent"`
+	ErrorSelectors []string `yaml:"error_selectors"` // 提交后采样的错误提示区域选择器
+	ResetStrategy  string   `yaml:"reset_strategy"`  // 两次尝试之间的页面重置策略: reload, in_place, reload_every_n
+	ResetEveryN    int      `yaml:"reset_every_n"`   // reload_every_n策略下每N次尝试重新加载一次
+	StepTimeout    int      `yaml:"step_timeout"`    // 分步登录中等待密码框出现的秒数
+	DedupeIdP      bool     `yaml:"dedupe_idp"`      // 多个目标跳转到同一IdP时只爆破第一个
 }
 
+// 页面重置策略
+const (
+	ResetReload       = "reload"         // 每次尝试前重新加载登录页面
+	ResetInPlace      = "in_place"       // 原地清空表单并关闭错误提示，页面失效时才重新加载
+	ResetReloadEveryN = "reload_every_n" // 原地重置，每N次尝试重新加载一次
+)
+
 // LoggingConfig 日志配置
 type LoggingConfig struct {
 	Level          string            `yaml:"level"`
@@ -74,11 +250,54 @@ type LogFileManagement struct {
 
 // ResultsConfig 结果存储配置
 type ResultsConfig struct {
-	SaveDir               string `yaml:"save_dir"`
-	SuccessFilenameFormat string `yaml:"success_filename_format"`
-	FailureFilenameFormat string `yaml:"failure_filename_format"`
-	Format                string `yaml:"format"`
-	RealtimeSave          bool   `yaml:"realtime_save"`
+	SaveDir               string              `yaml:"save_dir"`
+	SuccessFilenameFormat string              `yaml:"success_filename_format"`
+	FailureFilenameFormat string              `yaml:"failure_filename_format"`
+	RecordFilenameFormat  string              `yaml:"record_filename_format"` // 结构化结果记录(JSON Lines)文件名格式
+	Format                string              `yaml:"format"`
+	RealtimeSave          bool                `yaml:"realtime_save"`
+	Screenshot            ScreenshotConfig    `yaml:"screenshot"`
+	Evidence              EvidenceConfig      `yaml:"evidence"`
+	Recording             RecordingConfig     `yaml:"recording"`
+	SessionExport         SessionExportConfig `yaml:"session_export"`
+}
+
+// SessionExportConfig 登录成功后的会话导出配置
+type SessionExportConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Dir     string `yaml:"dir"` // 会话导出子目录（相对于save_dir）
+}
+
+// ScreenshotConfig 截图配置
+type ScreenshotConfig struct {
+	Enabled     bool   `yaml:"enabled"`
+	Dir         string `yaml:"dir"`          // 截图子目录（相对于save_dir）
+	FullPage    bool   `yaml:"full_page"`    // 是否截取整页（包含视口之外的内容）
+	Quality     int    `yaml:"quality"`      // 图片质量，100为PNG，其余为JPEG
+	OnSuccess   bool   `yaml:"on_success"`   // 登录成功时截图
+	OnSuspected bool   `yaml:"on_suspected"` // 疑似成功时截图
+	OnFailure   bool   `yaml:"on_failure"`   // 登录失败时截图
+	OnError     bool   `yaml:"on_error"`     // 尝试出错时截图
+}
+
+// EvidenceConfig 成功证据包配置
+type EvidenceConfig struct {
+	Enabled     bool   `yaml:"enabled"`
+	Dir         string `yaml:"dir"`          // 证据包子目录（相对于save_dir）
+	Format      string `yaml:"format"`       // 证据包格式: dir, zip
+	MaskCookies bool   `yaml:"mask_cookies"` // 是否遮蔽Cookie值
+	OnSuspected bool   `yaml:"on_suspected"` // 疑似成功时是否也生成证据包
+}
+
+// RecordingConfig 登录尝试录屏配置
+type RecordingConfig struct {
+	Enabled       bool   `yaml:"enabled"`         // 是否录制每次尝试（也可通过-record参数开启）
+	Dir           string `yaml:"dir"`             // 录屏保存子目录（相对于save_dir）
+	Format        string `yaml:"format"`          // 录屏格式: gif(动图), png(按序编号的PNG帧)
+	Quality       int    `yaml:"quality"`         // 录屏帧JPEG质量(0-100)
+	MaxWidth      int    `yaml:"max_width"`       // 录屏帧最大宽度
+	MaxHeight     int    `yaml:"max_height"`      // 录屏帧最大高度
+	EveryNthFrame int    `yaml:"every_nth_frame"` // 每N帧保留一帧
 }
 
 // CaptchaConfig 验证码配置
@@ -222,10 +441,39 @@ func LoadConfig(configPath string) (*Config, error) {
 		return nil, err
 	}
 
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
 	globalConfig = &config
 	return &config, nil
 }
 
+// validate 检查配置项，使用时会被静默忽略的错误在加载时报告
+func (c *Config) validate() error {
+	if !validResetStrategy(c.Bruteforce.ResetStrategy) {
+		return fmt.Errorf("bruteforce.reset_strategy 不是有效的重置策略 %q", c.Bruteforce.ResetStrategy)
+	}
+	for i, target := range c.Targets {
+		if _, err := regexp.Compile(target.Match); err != nil {
+			return fmt.Errorf("targets[%d].match 不是有效的正则表达式 %q: %v", i, target.Match, err)
+		}
+		if !validResetStrategy(target.Reset) {
+			return fmt.Errorf("targets[%d].reset 不是有效的重置策略 %q", i, target.Reset)
+		}
+	}
+	return nil
+}
+
+// validResetStrategy 检查页面重置策略，空字符串表示使用默认策略
+func validResetStrategy(strategy string) bool {
+	switch strategy {
+	case "", ResetReload, ResetInPlace, ResetReloadEveryN:
+		return true
+	}
+	return false
+}
+
 // GetConfig 获取全局配置
 func GetConfig() *Config {
 	return globalConfig
@@ -233,35 +481,96 @@ func GetConfig() *Config {
 
 // IsLoginPage 检查是否为登录页面
 func (c *Config) IsLoginPage(title, url, content string) bool {
+	_, matched := c.MatchLoginRule(title, url, content)
+	return matched
+}
+
+// MatchLoginRule 返回判定为登录页面的第一条配置规则，格式为 "配置项: 规则"。
+// 负分规则表示反向特征（如注册页面关键词），不作为登录页面的判定依据
+func (c *Config) MatchLoginRule(title, url, content string) (string, bool) {
+	detection := &c.LoginPageDetection
+
 	// 检查标题
-	for _, pattern := range c.LoginPageDetection.TitlePatterns {
+	for _, pattern := range detection.TitlePatterns {
 		if matched, _ := regexp.MatchString(pattern, title); matched {
-			return true
+			return "title_patterns: " + pattern, true
 		}
 	}
 
 	// 检查URL
-	for _, pattern := range c.LoginPageDetection.URLPatterns {
-		if matched, _ := regexp.MatchString(pattern, url); matched {
-			return true
+	for _, rule := range detection.URLPatterns {
+		if matched, _ := regexp.MatchString(rule.Pattern, url); matched && detection.RuleScore(rule) >= 0 {
+			return "url_patterns: " + rule.Pattern, true
 		}
 	}
 
 	// 检查内容关键词
-	for _, keyword := range c.LoginPageDetection.ContentKeywords {
-		if matched, _ := regexp.MatchString("(?i).*"+keyword+".*", content); matched {
-			return true
+	for _, rule := range detection.ContentKeywords {
+		if containsFold(content, rule.Pattern) && detection.RuleScore(rule) >= 0 {
+			return "content_keywords: " + rule.Pattern, true
 		}
 	}
 
 	// 检查标题关键词
-	for _, keyword := range c.LoginPageDetection.TitleKeywords {
-		if matched, _ := regexp.MatchString("(?i).*"+keyword+".*", title); matched {
-			return true
+	for _, rule := range detection.TitleKeywords {
+		if containsFold(title, rule.Pattern) && detection.RuleScore(rule) >= 0 {
+			return "title_keywords: " + rule.Pattern, true
 		}
 	}
 
-	return false
+	return "", false
+}
+
+// containsFold 不区分大小写的子串匹配
+func containsFold(s, substr string) bool {
+	return substr != "" && strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// GetTargetConfig 获取与目标URL匹配的目标配置（按配置顺序取第一个匹配项）
+func (c *Config) GetTargetConfig(url string) TargetConfig {
+	for _, target := range c.Targets {
+		if target.Match == "" {
+			continue
+		}
+		if matched, _ := regexp.MatchString(target.Match, url); matched {
+			return target
+		}
+	}
+	return TargetConfig{}
+}
+
+// GetResetStrategy 获取目标的页面重置策略及重新加载间隔
+func (c *Config) GetResetStrategy(url string) (string, int) {
+	strategy, every := c.Bruteforce.ResetStrategy, c.Bruteforce.ResetEveryN
+	target := c.GetTargetConfig(url)
+	if target.Reset != "" {
+		strategy = target.Reset
+	}
+	if target.ResetEveryN > 0 {
+		every = target.ResetEveryN
+	}
+
+	if strategy == "" {
+		strategy = ResetInPlace
+	}
+	return strategy, every
+}
+
+// GetEmulationProfile 获取目标URL使用的模拟配置档，未配置时返回空名称
+func (c *Config) GetEmulationProfile(url string) (string, *EmulationProfile) {
+	name := c.GetTargetConfig(url).Profile
+	if name == "" {
+		name = c.Emulation.DefaultProfile
+	}
+	if name == "" {
+		return "", nil
+	}
+
+	profile, ok := c.Emulation.Profiles[name]
+	if !ok {
+		return name, nil
+	}
+	return name, &profile
 }
 
 // GetUsernameSelectors 获取用户名选择器
@@ -288,6 +597,27 @@ func (c *Config) GetCheckboxSelectors() []string {
 	return c.FormElements.CheckboxSelectors
 }
 
+// GetNextSelectors 获取分步登录"下一步"按钮选择器
+func (c *Config) GetNextSelectors() []string {
+	return c.FormElements.NextSelectors
+}
+
+// GetStepTimeout 获取分步登录中等待下一步页面的秒数，未配置时为10秒
+func (c *Config) GetStepTimeout() int {
+	if c.Bruteforce.StepTimeout <= 0 {
+		return 10
+	}
+	return c.Bruteforce.StepTimeout
+}
+
+// GetMaxCrashRestarts 获取同一组凭据因浏览器崩溃重启的最大次数，未配置或为负数时为2次，0表示不重启
+func (c *Config) GetMaxCrashRestarts() int {
+	if c.Browser.MaxCrashRestarts == nil || *c.Browser.MaxCrashRestarts < 0 {
+		return 2
+	}
+	return *c.Browser.MaxCrashRestarts
+}
+
 // GetCredentials 获取凭据列表
 func (c *Config) GetCredentials() []Credential {
 	var credentials []Credential
@@ -3,22 +3,16 @@ package detector
 import (
 	"context"
 	"fmt"
-	"regexp"
 	"strings"
 	"time"
 
 	"github.com/chromedp/cdproto/cdp"
-	"github.com/chromedp/cdproto/network"
 	"github.com/chromedp/chromedp"
 	"github.com/sirupsen/logrus"
-	"golang.org/x/text/encoding/charmap"
-	"golang.org/x/text/encoding/japanese"
-	"golang.org/x/text/encoding/korean"
-	"golang.org/x/text/encoding/simplifiedchinese"
-	"golang.org/x/text/encoding/traditionalchinese"
 
 	"github.com/cyberspacesec/chrome_auto_login/pkg/browser"
 	"github.com/cyberspacesec/chrome_auto_login/pkg/config"
+	"github.com/cyberspacesec/chrome_auto_login/util"
 )
 
 // LoginFormElements 登录表单元素
@@ -31,21 +25,48 @@ type LoginFormElements struct {
 	HasCaptcha       bool         `json:"has_captcha"`
 	HasCheckbox      bool         `json:"has_checkbox"`
 	CaptchaInfo      *CaptchaInfo `json:"captcha_info"`
+	FormSelector     string       `json:"form_selector,omitempty"` // 字段所属的表单容器
+
+	// 各字段对应的具体节点，交互前通过Browser.Locate重新定位
+	UsernameRef *browser.ElementRef `json:"username_ref,omitempty"`
+	PasswordRef *browser.ElementRef `json:"password_ref,omitempty"`
+	CaptchaRef  *browser.ElementRef `json:"captcha_ref,omitempty"`
+	SubmitRef   *browser.ElementRef `json:"submit_ref,omitempty"`
+	CheckboxRef *browser.ElementRef `json:"checkbox_ref,omitempty"`
+
+	Honeypots []HoneypotField `json:"honeypots,omitempty"` // 登录表单内疑似蜜罐的字段
+
+	// 分步登录（先用户名后密码）
+	MultiStep    bool                `json:"multi_step,omitempty"`
+	NextSelector string              `json:"next_selector,omitempty"` // 第一步的"下一步"按钮
+	NextRef      *browser.ElementRef `json:"next_ref,omitempty"`
+	Steps        []LoginStep         `json:"steps,omitempty"` // 首次走通后记录的步骤序列
+
+	Candidates []FormCandidate `json:"-"` // 页面中所有表单候选，按得分排序
 }
 
 // PageAnalysis 页面分析结果
 type PageAnalysis struct {
-	Title            string             `json:"title"`
-	URL              string             `json:"url"`
-	IsLogin          bool               `json:"is_login"`
-	Confidence       float64            `json:"confidence"`
-	DetectedFeatures []string           `json:"detected_features"`
-	FormElements     *LoginFormElements `json:"form_elements"`
-	PageSource       string             `json:"page_source"`
-	Encoding         string             `json:"encoding"`
-	ResponseHeaders  map[string]string  `json:"response_headers"`
-	LoadTime         time.Duration      `json:"load_time"`
-	ErrorMessage     string             `json:"error_message"`
+	Title            string                   `json:"title"`
+	URL              string                   `json:"url"`
+	IsLogin          bool                     `json:"is_login"`
+	Confidence       float64                  `json:"confidence"`
+	DetectedFeatures []string                 `json:"detected_features"`
+	FormElements     *LoginFormElements       `json:"form_elements"`
+	PageSource       string                   `json:"page_source"`
+	Encoding         string                   `json:"encoding"`
+	EncodingSource   string                   `json:"encoding_source,omitempty"` // 编码的判定来源：bom/header/meta/browser/content
+	ResponseHeaders  map[string]string        `json:"response_headers"`
+	LoadTime         time.Duration            `json:"load_time"`
+	ErrorMessage     string                   `json:"error_message"`
+	Profile          string                   `json:"profile,omitempty"`
+	TLS              *browser.TLSInfo         `json:"tls,omitempty"`
+	Resolve          map[string]string        `json:"resolve,omitempty"`
+	Console          []browser.ConsoleMessage `json:"console,omitempty"`
+	Score            *ScoreBreakdown          `json:"score,omitempty"`           // 登录页面判定的得分明细
+	FormCandidates   []FormCandidate          `json:"form_candidates,omitempty"` // 页面中所有表单候选及得分
+	Render           *browser.RenderState     `json:"render,omitempty"`          // 客户端渲染等待结果
+	IdP              *IdentityProvider        `json:"idp,omitempty"`             // 登录所依赖的统一认证服务
 }
 
 // PageDetector 页面检测器
@@ -80,34 +101,27 @@ func NewPageDetector(browser *browser.Browser, cfg *config.Config, logger *logru
 func (pd *PageDetector) IsLoginPage() (bool, error) {
 	startTime := time.Now()
 
-	ctx, cancel := context.WithTimeout(pd.browser.GetContext(), pd.analysisTimeout)
-	defer cancel()
-
-	// 获取页面基本信息
-	var title, url, content string
-	err := chromedp.Run(ctx,
-		chromedp.Title(&title),
-		chromedp.Location(&url),
-		chromedp.Text("body", &content),
-	)
+	// 等待单页应用渲染完成后再读取页面信息
+	pd.waitForRender()
 
+	breakdown, err := pd.evaluateLoginPage()
 	if err != nil {
 		pd.logger.Warnf("⚠️ 获取页面信息失败: %v", err)
 		return false, err
 	}
 
-	// 使用配置规则检测
-	isLogin := pd.config.IsLoginPage(title, url, content)
-
-	// 增强检测逻辑
-	confidence := pd.calculateLoginConfidence(title, url, content, ctx)
+	// 放弃前检查哈希路由跳转
+	if !breakdown.IsLogin {
+		if recheck := pd.recheckHashRoutes(); recheck != nil {
+			breakdown = recheck
+		}
+	}
+	isLogin := breakdown.IsLogin
 
 	loadTime := time.Since(startTime)
-	pd.logger.Debugf("页面检测完成，用时: %v, 置信度: %.2f", loadTime, confidence)
-
-	// 置信度阈值判断
-	if confidence >= 0.6 {
-		isLogin = true
+	pd.logger.Debugf("页面检测完成，用时: %v, 置信度: %.2f (阈值 %.2f)", loadTime, breakdown.Confidence, breakdown.Threshold)
+	if breakdown.ConfigRule != "" {
+		pd.logger.Debugf("命中配置规则: %s", breakdown.ConfigRule)
 	}
 
 	if isLogin {
@@ -119,160 +133,16 @@ func (pd *PageDetector) IsLoginPage() (bool, error) {
 	return isLogin, nil
 }
 
-// calculateLoginConfidence 计算登录页面置信度
-func (pd *PageDetector) calculateLoginConfidence(title, url, content string, ctx context.Context) float64 {
-	var confidence float64
-
-	// 标题权重: 30%
-	titleScore := pd.checkTitleFeatures(title)
-	confidence += titleScore * 0.3
-
-	// URL权重: 20%
-	urlScore := pd.checkURLFeatures(url)
-	confidence += urlScore * 0.2
-
-	// 内容权重: 25%
-	contentScore := pd.checkContentFeatures(content)
-	confidence += contentScore * 0.25
-
-	// 表单元素权重: 25%
-	formScore := pd.checkFormFeatures(ctx)
-	confidence += formScore * 0.25
-
-	return confidence
-}
-
-// checkTitleFeatures 检查标题特征
-func (pd *PageDetector) checkTitleFeatures(title string) float64 {
-	loginKeywords := []string{
-		"登录", "登陆", "login", "sign in", "log in", "signin",
-		"用户登录", "管理员登录", "后台登录", "系统登录",
-		"admin", "administration", "后台管理", "管理系统",
-		"auth", "authentication", "portal", "gateway",
-	}
-
-	title = strings.ToLower(title)
-	score := 0.0
-
-	for _, keyword := range loginKeywords {
-		if strings.Contains(title, strings.ToLower(keyword)) {
-			if strings.Contains(keyword, "login") || strings.Contains(keyword, "登录") {
-				score += 0.4 // 核心关键词更高权重
-			} else {
-				score += 0.2
-			}
-		}
-	}
-
-	if score > 1.0 {
-		score = 1.0
-	}
-
-	return score
-}
-
-// checkURLFeatures 检查URL特征
-func (pd *PageDetector) checkURLFeatures(url string) float64 {
-	urlPatterns := []string{
-		`(?i).*/login.*`,
-		`(?i).*/signin.*`,
-		`(?i).*/auth.*`,
-		`(?i).*/admin.*`,
-		`(?i).*/user.*`,
-		`(?i).*/portal.*`,
-		`(?i).*/sso.*`,
-		`(?i).*/oauth.*`,
-	}
-
-	score := 0.0
-	url = strings.ToLower(url)
-
-	for _, pattern := range urlPatterns {
-		if matched, _ := regexp.MatchString(pattern, url); matched {
-			score += 0.3
-		}
-	}
-
-	if score > 1.0 {
-		score = 1.0
-	}
-
-	return score
-}
-
-// checkContentFeatures 检查内容特征
-func (pd *PageDetector) checkContentFeatures(content string) float64 {
-	contentKeywords := map[string]float64{
-		"用户名":      0.15,
-		"密码":       0.15,
-		"username": 0.15,
-		"password": 0.15,
-		"登录":       0.1,
-		"login":    0.1,
-		"账号":       0.1,
-		"邮箱":       0.05,
-		"手机号":      0.05,
-		"验证码":      0.05,
-		"captcha":  0.05,
-		"记住我":      0.03,
-		"忘记密码":     0.03,
-		"注册":       -0.05, // 注册页面降低分数
-		"register": -0.05,
-	}
-
-	content = strings.ToLower(content)
-	score := 0.0
-
-	for keyword, weight := range contentKeywords {
-		if strings.Contains(content, strings.ToLower(keyword)) {
-			score += weight
-		}
-	}
-
-	if score > 1.0 {
-		score = 1.0
-	} else if score < 0 {
-		score = 0.0
-	}
-
-	return score
-}
-
-// checkFormFeatures 检查表单特征
-func (pd *PageDetector) checkFormFeatures(ctx context.Context) float64 {
-	score := 0.0
-
-	// 检查用户名输入框
-	usernameFound := pd.checkElementsExist(ctx, pd.config.GetUsernameSelectors())
-	if usernameFound {
-		score += 0.4
-	}
-
-	// 检查密码输入框
-	passwordFound := pd.checkElementsExist(ctx, pd.config.GetPasswordSelectors())
-	if passwordFound {
-		score += 0.4
-	}
-
-	// 检查提交按钮
-	submitFound := pd.checkElementsExist(ctx, pd.config.GetSubmitSelectors())
-	if submitFound {
-		score += 0.2
-	}
-
-	return score
-}
-
-// checkElementsExist 检查元素是否存在
-func (pd *PageDetector) checkElementsExist(ctx context.Context, selectors []string) bool {
+// findExistingSelector 返回第一个在页面中存在元素的选择器
+func (pd *PageDetector) findExistingSelector(ctx context.Context, selectors []string) string {
 	for _, selector := range selectors {
 		var nodes []*cdp.Node
-		err := chromedp.Run(ctx, chromedp.Nodes(selector, &nodes))
+		err := chromedp.Run(ctx, chromedp.Nodes(selector, &nodes, chromedp.AtLeast(0)))
 		if err == nil && len(nodes) > 0 {
-			return true
+			return selector
 		}
 	}
-	return false
+	return ""
 }
 
 // DetectLoginForm 检测登录表单元素
@@ -281,9 +151,35 @@ func (pd *PageDetector) DetectLoginForm() (*LoginFormElements, error) {
 
 	elements := &LoginFormElements{}
 
+	// 先按表单容器打分，字段取自同一个得分最高的容器
+	ctx, cancel := context.WithTimeout(pd.browser.GetContext(), pd.elementDetectTimeout)
+	defer cancel()
+	candidates, candidatesErr := pd.FindFormCandidates(ctx)
+	if candidatesErr != nil {
+		pd.logger.Warnf("⚠️ %v", candidatesErr)
+	}
+	elements.Candidates = candidates
+	if best := bestFormCandidate(candidates); best != nil {
+		elements.FormSelector = best.Selector
+		elements.UsernameSelector = best.UsernameSelector
+		elements.PasswordSelector = best.PasswordSelector
+		elements.SubmitSelector = best.SubmitSelector
+		elements.CaptchaSelector = best.CaptchaSelector
+		elements.CheckboxSelector = best.CheckboxSelector
+		elements.HasCheckbox = best.CheckboxSelector != ""
+		elements.Honeypots = best.Honeypots
+		pd.logger.Debugf("✅ 选中登录表单 %s (得分 %.2f，共 %d 个候选)", best.Selector, best.Score, len(candidates))
+	} else if len(candidates) > 0 {
+		pd.logger.Debugf("表单候选得分均低于 %.2f，按全局规则查找字段", formCandidateThreshold)
+	}
+
+	// 选中表单缺少的字段只在该表单内补充查找，避免页头搜索、订阅等按钮被当成登录字段
 	// 检测用户名输入框
-	usernameSelector, err := pd.browser.FindElement(pd.config.GetUsernameSelectors())
-	if err == nil && usernameSelector != "" {
+	usernameSelector := elements.UsernameSelector
+	if usernameSelector == "" {
+		usernameSelector = pd.findInForm(elements.FormSelector, pd.config.GetUsernameSelectors())
+	}
+	if usernameSelector != "" {
 		elements.UsernameSelector = usernameSelector
 		pd.logger.Debugf("✅ 发现用户名输入框: %s", usernameSelector)
 	} else {
@@ -291,50 +187,143 @@ func (pd *PageDetector) DetectLoginForm() (*LoginFormElements, error) {
 	}
 
 	// 检测密码输入框
-	passwordSelector, err := pd.browser.FindElement(pd.config.GetPasswordSelectors())
-	if err == nil && passwordSelector != "" {
+	passwordSelector := elements.PasswordSelector
+	if passwordSelector == "" {
+		passwordSelector = pd.findInForm(elements.FormSelector, pd.config.GetPasswordSelectors())
+	}
+	if passwordSelector != "" {
 		elements.PasswordSelector = passwordSelector
 		pd.logger.Debugf("✅ 发现密码输入框: %s", passwordSelector)
+	} else if elements.UsernameSelector != "" {
+		pd.logger.Debug("当前页面只有用户名输入框，密码框可能在下一步出现")
 	} else {
 		pd.logger.Warn("⚠️ 未找到密码输入框")
 	}
 
 	// 检测验证码
-	captchaInfo, err := pd.captchaDetector.DetectCaptcha()
-	if err == nil && captchaInfo != nil && captchaInfo.Type != CaptchaTypeNone {
+	if captchaInfo, err := pd.captchaDetector.DetectCaptcha(); err == nil && captchaInfo != nil && captchaInfo.Type != CaptchaTypeNone {
 		elements.HasCaptcha = true
 		elements.CaptchaInfo = captchaInfo
 		elements.CaptchaSelector = captchaInfo.Selector
 
-		// 如果有验证码输入框，也尝试找到它
-		if captchaSelector, err := pd.browser.FindElement(pd.config.GetCaptchaSelectors()); err == nil && captchaSelector != "" {
+		// 如果有验证码输入框，也尝试找到它（优先使用登录表单内的输入框）
+		if best := bestFormCandidate(candidates); best != nil && best.CaptchaSelector != "" {
+			elements.CaptchaSelector = best.CaptchaSelector
+		} else if captchaSelector := pd.findInForm(elements.FormSelector, pd.config.GetCaptchaSelectors()); captchaSelector != "" {
 			elements.CaptchaSelector = captchaSelector
 		}
+	} else if elements.CaptchaSelector != "" && !elements.HasCaptcha {
+		// 表单内命中验证码规则但未识别出验证码类型，不作为验证码处理
+		elements.CaptchaSelector = ""
 	}
 
 	// 检测复选框（如用户协议）
-	checkboxSelector, err := pd.browser.FindElement(pd.config.GetCheckboxSelectors())
-	if err == nil && checkboxSelector != "" {
+	checkboxSelector := elements.CheckboxSelector
+	if checkboxSelector == "" {
+		checkboxSelector = pd.findInForm(elements.FormSelector, pd.config.GetCheckboxSelectors())
+	}
+	if checkboxSelector != "" {
 		elements.CheckboxSelector = checkboxSelector
 		elements.HasCheckbox = true
 		pd.logger.Debugf("✅ 发现复选框: %s", checkboxSelector)
 	}
 
 	// 检测提交按钮
-	submitSelector, err := pd.browser.FindElement(pd.config.GetSubmitSelectors())
-	if err == nil && submitSelector != "" {
+	submitSelector := elements.SubmitSelector
+	if submitSelector == "" {
+		submitSelector = pd.findInForm(elements.FormSelector, pd.config.GetSubmitSelectors())
+	}
+	if submitSelector != "" {
 		elements.SubmitSelector = submitSelector
 		pd.logger.Debugf("✅ 发现提交按钮: %s", submitSelector)
 	} else {
 		pd.logger.Warn("⚠️ 未找到提交按钮")
 	}
 
+	pd.markMultiStep(elements)
+
+	for _, field := range elements.Honeypots {
+		pd.logger.Warnf("🍯 发现疑似蜜罐字段 %s (%s)，不会填写", field.Selector, strings.Join(field.Reasons, ", "))
+	}
+
+	// 把匹配规则解析为具体节点的唯一选择器
+	elements.UsernameRef = pd.resolveElement(&elements.UsernameSelector)
+	elements.PasswordRef = pd.resolveElement(&elements.PasswordSelector)
+	elements.CaptchaRef = pd.resolveElement(&elements.CaptchaSelector)
+	elements.SubmitRef = pd.resolveElement(&elements.SubmitSelector)
+	elements.CheckboxRef = pd.resolveElement(&elements.CheckboxSelector)
+	elements.NextRef = pd.resolveElement(&elements.NextSelector)
+
 	detectTime := time.Since(startTime)
 	pd.logger.Debugf("表单元素检测完成，用时: %v", detectTime)
 
 	return elements, nil
 }
 
+// findVisible 按全局规则查找第一个可见元素，返回其唯一选择器
+func (pd *PageDetector) findVisible(selectors []string) string {
+	ref, err := pd.browser.FindVisibleElement(selectors)
+	if err != nil {
+		pd.logger.Debugf("%v", err)
+		return ""
+	}
+	if ref == nil {
+		return ""
+	}
+	return ref.Selector
+}
+
+// findInForm 在选中的登录表单内按规则查找可见元素，没有选中表单时按全局规则查找
+func (pd *PageDetector) findInForm(form string, selectors []string) string {
+	if form == "" {
+		return pd.findVisible(selectors)
+	}
+	scoped := make([]string, 0, len(selectors))
+	for _, selector := range selectors {
+		scoped = append(scoped, scopeSelector(form, selector))
+	}
+	return pd.findVisible(scoped)
+}
+
+// scopeSelector 把规则限定在容器内，逗号分隔的每一项都加上容器前缀（括号和引号内的逗号除外）
+func scopeSelector(container, selector string) string {
+	var parts []string
+	depth, quote, start := 0, rune(0), 0
+	for i, r := range selector {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '(' || r == '[':
+			depth++
+		case r == ')' || r == ']':
+			depth--
+		case r == ',' && depth == 0:
+			parts = append(parts, container+" "+strings.TrimSpace(selector[start:i]))
+			start = i + 1
+		}
+	}
+	parts = append(parts, container+" "+strings.TrimSpace(selector[start:]))
+	return strings.Join(parts, ", ")
+}
+
+// resolveElement 把选择器解析为具体节点，成功时用唯一选择器替换原选择器
+func (pd *PageDetector) resolveElement(selector *string) *browser.ElementRef {
+	if *selector == "" {
+		return nil
+	}
+	ref, err := pd.browser.ResolveElement(*selector)
+	if err != nil {
+		pd.logger.Debugf("保留匹配规则 %s: %v", *selector, err)
+		return nil
+	}
+	*selector = ref.Selector
+	return ref
+}
+
 // AnalyzePage 分析页面（增强版，包含源码）
 func (pd *PageDetector) AnalyzePage() (*PageAnalysis, error) {
 	startTime := time.Now()
@@ -344,39 +333,19 @@ func (pd *PageDetector) AnalyzePage() (*PageAnalysis, error) {
 		ResponseHeaders:  make(map[string]string),
 	}
 
-	// 设置网络监听来获取响应头
 	ctx := pd.browser.GetContext()
 
-	// 启用网络域
-	if err := chromedp.Run(ctx, network.Enable()); err != nil {
-		pd.logger.Warnf("启用网络监听失败: %v", err)
-	}
-
-	// 监听响应头
-	chromedp.ListenTarget(ctx, func(ev interface{}) {
-		switch ev := ev.(type) {
-		case *network.EventResponseReceived:
-			if strings.Contains(ev.Response.URL, "login") || ev.Type == network.ResourceTypeDocument {
-				for key, value := range ev.Response.Headers {
-					if key == "content-type" || key == "Content-Type" {
-						analysis.ResponseHeaders["content-type"] = fmt.Sprintf("%v", value)
-					}
-					if key == "content-encoding" || key == "Content-Encoding" {
-						analysis.ResponseHeaders["content-encoding"] = fmt.Sprintf("%v", value)
-					}
-				}
-			}
-		}
-	})
+	// 等待页面加载完成：启用渲染等待时等待DOM稳定，否则固定等待
+	analysis.Render = pd.waitForRender()
+	if analysis.Render == nil {
+		time.Sleep(2 * time.Second)
+	}
 
-	// 等待页面完全加载
 	analyzeCtx, cancel := context.WithTimeout(ctx, pd.analysisTimeout)
 	defer cancel()
 
 	var title, url, content, pageSource string
 	err := chromedp.Run(analyzeCtx,
-		// 等待页面加载完成
-		chromedp.Sleep(2*time.Second),
 		// 获取基本信息
 		chromedp.Title(&title),
 		chromedp.Location(&url),
@@ -391,28 +360,31 @@ func (pd *PageDetector) AnalyzePage() (*PageAnalysis, error) {
 		return analysis, err
 	}
 
-	// 检测页面编码
-	encoding := pd.detectEncoding(pageSource, analysis.ResponseHeaders)
-	analysis.Encoding = encoding
-
-	// 如果需要，转换编码
-	if encoding != "utf-8" && encoding != "UTF-8" {
-		if convertedSource, err := pd.convertEncoding(pageSource, encoding); err == nil {
-			pageSource = convertedSource
-			pd.logger.Debugf("页面编码已从 %s 转换为 UTF-8", encoding)
-		}
-	}
+	// 页面源码取自DOM，已是UTF-8，编码只用于报告
+	analysis.Encoding, analysis.EncodingSource = pd.detectPageEncoding(analyzeCtx, analysis.ResponseHeaders)
 
 	// 基本信息
 	analysis.Title = title
 	analysis.URL = url
 	analysis.PageSource = pageSource
 	analysis.LoadTime = time.Since(startTime)
+	analysis.Profile = pd.browser.EmulationProfile()
+	analysis.TLS = pd.browser.TLSInfo()
+	analysis.Resolve = pd.browser.HostResolver()
+	analysis.Console = pd.browser.ConsoleMessages()
+	analysis.IdP = pd.DetectIdentityProvider()
+	if analysis.IdP != nil {
+		analysis.DetectedFeatures = append(analysis.DetectedFeatures, "统一认证("+analysis.IdP.Product+")")
+	}
+	if analysis.TLS.HasCertificateError() {
+		analysis.DetectedFeatures = append(analysis.DetectedFeatures, "证书异常")
+	}
 
 	// 登录页面检测
-	confidence := pd.calculateLoginConfidence(title, url, content, analyzeCtx)
-	analysis.Confidence = confidence
-	analysis.IsLogin = confidence >= 0.6
+	breakdown := pd.scoreLoginPage(title, url, content, analyzeCtx)
+	analysis.Score = breakdown
+	analysis.Confidence = breakdown.Confidence
+	analysis.IsLogin = breakdown.IsLogin
 
 	// 特征检测
 	if analysis.IsLogin {
@@ -422,6 +394,7 @@ func (pd *PageDetector) AnalyzePage() (*PageAnalysis, error) {
 	// 检测表单元素
 	if formElements, err := pd.DetectLoginForm(); err == nil {
 		analysis.FormElements = formElements
+		analysis.FormCandidates = formElements.Candidates
 
 		if formElements.UsernameSelector != "" {
 			analysis.DetectedFeatures = append(analysis.DetectedFeatures, "用户名输入框")
@@ -438,6 +411,12 @@ func (pd *PageDetector) AnalyzePage() (*PageAnalysis, error) {
 		if formElements.SubmitSelector != "" {
 			analysis.DetectedFeatures = append(analysis.DetectedFeatures, "提交按钮")
 		}
+		if formElements.MultiStep {
+			analysis.DetectedFeatures = append(analysis.DetectedFeatures, "分步登录")
+		}
+		if len(formElements.Honeypots) > 0 {
+			analysis.DetectedFeatures = append(analysis.DetectedFeatures, fmt.Sprintf("蜜罐字段(%d个)", len(formElements.Honeypots)))
+		}
 	}
 
 	pd.logger.Infof("✅ 页面分析完成，用时: %v, 置信度: %.2f", analysis.LoadTime, analysis.Confidence)
@@ -445,110 +424,41 @@ func (pd *PageDetector) AnalyzePage() (*PageAnalysis, error) {
 	return analysis, nil
 }
 
-// detectEncoding 检测页面编码
-func (pd *PageDetector) detectEncoding(pageSource string, headers map[string]string) string {
-	// 1. 从HTTP响应头检测
-	if contentType, exists := headers["content-type"]; exists {
-		if matched := regexp.MustCompile(`charset=([^;]+)`).FindStringSubmatch(contentType); len(matched) > 1 {
-			encoding := strings.TrimSpace(matched[1])
-			pd.logger.Debugf("从HTTP头检测到编码: %s", encoding)
-			return encoding
+// detectPageEncoding 根据导航时捕获的响应头和Network.getResponseBody取得的文档字节检测页面编码，返回编码和判定来源
+func (pd *PageDetector) detectPageEncoding(ctx context.Context, headers map[string]string) (string, string) {
+	doc := pd.browser.Document()
+	if doc != nil {
+		for _, key := range []string{"content-type", "content-encoding"} {
+			if value, ok := doc.Headers[key]; ok {
+				headers[key] = value
+			}
 		}
 	}
+	contentType := headers["content-type"]
 
-	// 2. 从HTML meta标签检测
-	metaPatterns := []string{
-		`<meta\s+charset=["']?([^"'>\s]+)["']?`,
-		`<meta\s+http-equiv=["']?content-type["']?\s+content=["']?[^"']*charset=([^"'>\s]+)["']?`,
-		`<meta\s+content=["']?[^"']*charset=([^"'>\s]+)["']?\s+http-equiv=["']?content-type["']?`,
-	}
-
-	pageSourceLower := strings.ToLower(pageSource)
-	for _, pattern := range metaPatterns {
-		if matched := regexp.MustCompile(pattern).FindStringSubmatch(pageSourceLower); len(matched) > 1 {
-			encoding := strings.TrimSpace(matched[1])
-			pd.logger.Debugf("从HTML meta标签检测到编码: %s", encoding)
-			return encoding
-		}
+	body, raw, err := pd.browser.DocumentBody()
+	if err != nil {
+		pd.logger.Debugf("无法获取文档原始内容: %v", err)
 	}
 
-	// 3. 根据内容特征推测编码
-	if strings.Contains(pageSource, "中文") || strings.Contains(pageSource, "登录") {
-		if isGBK(pageSource) {
-			pd.logger.Debug("根据内容特征推测编码: GBK")
-			return "GBK"
+	// 原始字节可以完整检测；Chrome已解码为文本时只有BOM、响应头和meta声明可信，内容推测没有意义
+	if len(body) > 0 {
+		charset, source := util.DetectEncoding(body, contentType)
+		if raw || source != util.EncodingFromContent {
+			pd.logger.Debugf("从%s检测到页面编码: %s", source, charset)
+			return charset, source
 		}
+	} else if charset := util.CharsetFromContentType(contentType); charset != "" {
+		return charset, util.EncodingFromHeader
 	}
 
-	// 4. 默认编码
-	pd.logger.Debug("使用默认编码: UTF-8")
-	return "UTF-8"
-}
-
-// isGBK 简单判断是否可能是GBK编码
-func isGBK(content string) bool {
-	// 通过字节模式简单判断
-	bytes := []byte(content)
-	gbkCount := 0
-	for i := 0; i < len(bytes)-1; i++ {
-		if bytes[i] >= 0x81 && bytes[i] <= 0xFE && bytes[i+1] >= 0x40 && bytes[i+1] <= 0xFE {
-			gbkCount++
+	// 没有声明时使用浏览器实际采用的编码
+	var characterSet string
+	if err := chromedp.Run(ctx, chromedp.Evaluate(`document.characterSet`, &characterSet)); err == nil {
+		if charset := util.NormalizeCharset(characterSet); charset != "" {
+			pd.logger.Debugf("使用浏览器判定的页面编码: %s", charset)
+			return charset, util.EncodingFromBrowser
 		}
 	}
-	return gbkCount > len(bytes)/20 // 如果GBK特征字节超过5%
-}
-
-// convertEncoding 转换编码
-func (pd *PageDetector) convertEncoding(content, fromEncoding string) (string, error) {
-	fromEncoding = strings.ToUpper(fromEncoding)
-
-	switch fromEncoding {
-	case "GBK", "GB2312", "GB18030":
-		if decoder := simplifiedchinese.GBK.NewDecoder(); decoder != nil {
-			result, err := decoder.String(content)
-			if err == nil {
-				return result, nil
-			}
-		}
-	case "BIG5":
-		if decoder := traditionalchinese.Big5.NewDecoder(); decoder != nil {
-			result, err := decoder.String(content)
-			if err == nil {
-				return result, nil
-			}
-		}
-	case "SHIFT_JIS", "SHIFT-JIS":
-		if decoder := japanese.ShiftJIS.NewDecoder(); decoder != nil {
-			result, err := decoder.String(content)
-			if err == nil {
-				return result, nil
-			}
-		}
-	case "ISO-2022-JP":
-		if decoder := japanese.ISO2022JP.NewDecoder(); decoder != nil {
-			result, err := decoder.String(content)
-			if err == nil {
-				return result, nil
-			}
-		}
-	case "EUC-KR":
-		if decoder := korean.EUCKR.NewDecoder(); decoder != nil {
-			result, err := decoder.String(content)
-			if err == nil {
-				return result, nil
-			}
-		}
-	case "ISO-8859-1", "LATIN1":
-		if decoder := charmap.ISO8859_1.NewDecoder(); decoder != nil {
-			result, err := decoder.String(content)
-			if err == nil {
-				return result, nil
-			}
-		}
-	default:
-		return content, fmt.Errorf("不支持的编码: %s", fromEncoding)
-	}
-
-	// 如果转换失败，返回原内容
-	return content, nil
+	return "UTF-8", util.EncodingFromContent
 }
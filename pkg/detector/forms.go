@@ -0,0 +1,255 @@
+package detector
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/chromedp/chromedp"
+
+	"github.com/cyberspacesec/chrome_auto_login/pkg/browser"
+)
+
+// 表单候选打分权重
+const (
+	formScorePassword      = 0.4  // 包含密码框
+	formScoreUsername      = 0.25 // 包含用户名输入框
+	formScoreSubmit        = 0.15 // 包含提交按钮
+	formScoreFormTag       = 0.05 // 是真正的<form>元素
+	formScoreLoginAttr     = 0.1  // action/id/class中带登录关键字
+	formScoreLoginText     = 0.05 // 容器文本中带登录字样
+	formPenaltySearch      = -0.3 // 搜索框且没有密码框
+	formPenaltyMultiPass   = -0.2 // 多个密码框（注册、改密表单）
+	formCandidateThreshold = 0.3  // 低于此分数的容器不作为登录表单
+)
+
+// formLoginAttrKeywords 表单属性中的登录关键字
+var formLoginAttrKeywords = []string{"login", "signin", "sign-in", "sign_in", "logon", "auth", "passport", "sso", "session"}
+
+// formLoginTextKeywords 表单文本中的登录字样
+var formLoginTextKeywords = []string{"登录", "登 录", "登陆", "login", "log in", "sign in"}
+
+// FormCandidate 页面中的一个表单或类表单容器
+type FormCandidate struct {
+	Selector         string   `json:"selector"`
+	Tag              string   `json:"tag"`
+	Score            float64  `json:"score"`
+	Reasons          []string `json:"reasons"`
+	UsernameSelector string   `json:"username_selector,omitempty"`
+	PasswordSelector string   `json:"password_selector,omitempty"`
+	SubmitSelector   string   `json:"submit_selector,omitempty"`
+	CaptchaSelector  string   `json:"captcha_selector,omitempty"`
+	CheckboxSelector string   `json:"checkbox_selector,omitempty"`
+	IsForm           bool     `json:"is_form"`
+	PasswordCount    int      `json:"password_count"`
+	HasSearch        bool     `json:"has_search"`
+	LoginAttr        bool     `json:"login_attr"`
+	LoginText        bool     `json:"login_text"`
+
+	Honeypots []HoneypotField `json:"honeypots,omitempty"` // 容器内疑似蜜罐的字段
+}
+
+// HoneypotField 看不见但可填写的输入框
+type HoneypotField struct {
+	Selector string   `json:"selector"`
+	Name     string   `json:"name"`
+	Reasons  []string `json:"reasons"`
+}
+
+// formRules 传给页面脚本的识别规则
+type formRules struct {
+	Username  []string `json:"username"`
+	Password  []string `json:"password"`
+	Submit    []string `json:"submit"`
+	Captcha   []string `json:"captcha"`
+	Checkbox  []string `json:"checkbox"`
+	LoginAttr []string `json:"loginAttr"`
+	LoginText []string `json:"loginText"`
+}
+
+// findFormsScript 枚举<form>和包含密码框的类表单容器，并在每个容器内按规则查找可见字段
+var findFormsScript = `function(rules) {
+	const hiddenState = ` + browser.HiddenStateScript + `;
+	const esc = (s) => (window.CSS && CSS.escape) ? CSS.escape(s) : String(s).replace(/([^\w-])/g, '\\$1');
+	const uniqueID = (el) => el.id && document.querySelectorAll('#' + esc(el.id)).length === 1;
+
+	// 从最近的唯一id祖先开始，用nth-of-type构造路径
+	const cssPath = (el) => {
+		const parts = [];
+		for (let cur = el; cur && cur.nodeType === 1; cur = cur.parentElement) {
+			if (uniqueID(cur)) {
+				parts.unshift('#' + esc(cur.id));
+				return parts.join(' > ');
+			}
+			if (cur === document.documentElement) {
+				parts.unshift('html');
+				break;
+			}
+			let index = 1;
+			for (let sib = cur.previousElementSibling; sib; sib = sib.previousElementSibling) {
+				if (sib.tagName === cur.tagName) index++;
+			}
+			parts.unshift(cur.tagName.toLowerCase() + ':nth-of-type(' + index + ')');
+		}
+		return parts.join(' > ');
+	};
+
+	// :contains("文本") 不是标准CSS，拆成基础选择器加文本过滤
+	const match = (root, rule) => {
+		const m = rule.match(/^(.*):contains\((["']?)(.*)\2\)\s*$/);
+		let els;
+		try {
+			els = Array.from(root.querySelectorAll(m ? (m[1] || '*') : rule));
+		} catch (e) {
+			return [];
+		}
+		if (m) {
+			els = els.filter((el) => (el.textContent || el.value || '').includes(m[3]));
+		}
+		return els;
+	};
+
+	// 容器内第一个命中规则的可见元素；能用"容器 规则"唯一定位时使用它，否则使用CSS路径
+	const pick = (container, path, list, accept) => {
+		for (const rule of list || []) {
+			const el = match(container, rule).find((e) => (!accept || accept(e)) && hiddenState(e).reasons.length === 0);
+			if (!el) continue;
+			if (rule.indexOf(':contains(') < 0 && rule.indexOf(',') < 0) {
+				const scoped = path + ' ' + rule;
+				try {
+					if (document.querySelector(scoped) === el) return scoped;
+				} catch (e) {}
+			}
+			return cssPath(el);
+		}
+		return '';
+	};
+
+	const isText = (el) => el.tagName !== 'INPUT' || !['password', 'hidden', 'checkbox', 'radio', 'submit', 'button', 'image', 'file'].includes((el.type || '').toLowerCase());
+	const hasAny = (text, words) => words.some((w) => text.includes(w));
+
+	// 收集容器：所有<form>，以及不在<form>内的密码框向上找到的同时包含按钮和文本框的祖先
+	const containers = new Set(document.querySelectorAll('form'));
+	document.querySelectorAll('input[type="password"]').forEach((pw) => {
+		if (pw.form || pw.closest('form')) return;
+		let cur = pw.parentElement;
+		while (cur && cur !== document.body) {
+			const hasButton = cur.querySelector('button, input[type="submit"], input[type="button"], [role="button"], a');
+			const hasText = cur.querySelector('input:not([type]), input[type="text"], input[type="email"], input[type="tel"]');
+			if (hasButton && hasText) break;
+			cur = cur.parentElement;
+		}
+		containers.add(cur || document.body);
+	});
+
+	return Array.from(containers).map((container) => {
+		const path = cssPath(container);
+		const attrs = ['action', 'id', 'class', 'name'].map((a) => container.getAttribute(a) || '').join(' ').toLowerCase();
+		const text = (container.innerText || container.textContent || '').toLowerCase();
+		const hasSearch = container.getAttribute('role') === 'search' || attrs.includes('search') ||
+			!!container.querySelector('input[type="search"], input[name*="search" i], input[name="q"], input[placeholder*="搜索"], input[placeholder*="search" i]');
+
+		// 看不见但可填写的输入框，填写后可能触发反自动化检测
+		const honeypots = [];
+		container.querySelectorAll('input, textarea').forEach((el) => {
+			const state = hiddenState(el);
+			if (state.honeypot) {
+				honeypots.push({ selector: cssPath(el), name: el.getAttribute('name') || el.id || '', reasons: state.reasons });
+			}
+		});
+
+		return {
+			selector: path,
+			tag: container.tagName.toLowerCase(),
+			username_selector: pick(container, path, rules.username, isText),
+			password_selector: pick(container, path, rules.password, (e) => (e.type || '').toLowerCase() === 'password'),
+			submit_selector: pick(container, path, rules.submit),
+			captcha_selector: pick(container, path, rules.captcha, isText),
+			checkbox_selector: pick(container, path, rules.checkbox),
+			is_form: container.tagName === 'FORM',
+			password_count: container.querySelectorAll('input[type="password"]').length,
+			has_search: hasSearch,
+			login_attr: hasAny(attrs, rules.loginAttr),
+			login_text: hasAny(text, rules.loginText),
+			honeypots: honeypots,
+		};
+	});
+}`
+
+// FindFormCandidates 枚举页面中所有表单和类表单容器，按登录表单的可能性从高到低排序
+func (pd *PageDetector) FindFormCandidates(ctx context.Context) ([]FormCandidate, error) {
+	rules := formRules{
+		Username:  nonNil(pd.config.GetUsernameSelectors()),
+		Password:  nonNil(pd.config.GetPasswordSelectors()),
+		Submit:    nonNil(pd.config.GetSubmitSelectors()),
+		Captcha:   nonNil(pd.config.GetCaptchaSelectors()),
+		Checkbox:  nonNil(pd.config.GetCheckboxSelectors()),
+		LoginAttr: formLoginAttrKeywords,
+		LoginText: formLoginTextKeywords,
+	}
+
+	var candidates []FormCandidate
+	if err := chromedp.Run(ctx, browser.CallFunction(findFormsScript, &candidates, rules)); err != nil {
+		return nil, fmt.Errorf("枚举表单失败: %v", err)
+	}
+
+	for i := range candidates {
+		scoreFormCandidate(&candidates[i])
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+	return candidates, nil
+}
+
+// scoreFormCandidate 计算容器作为登录表单的得分，并记录得分原因
+func scoreFormCandidate(c *FormCandidate) {
+	c.Score = 0
+	c.Reasons = []string{}
+	add := func(score float64, reason string) {
+		c.Score += score
+		c.Reasons = append(c.Reasons, fmt.Sprintf("%+.2f %s", score, reason))
+	}
+
+	if c.PasswordSelector != "" {
+		add(formScorePassword, "密码框")
+	}
+	if c.UsernameSelector != "" {
+		add(formScoreUsername, "用户名输入框")
+	}
+	if c.SubmitSelector != "" {
+		add(formScoreSubmit, "提交按钮")
+	}
+	if c.IsForm {
+		add(formScoreFormTag, "<form>元素")
+	}
+	if c.LoginAttr {
+		add(formScoreLoginAttr, "属性含登录关键字")
+	}
+	if c.LoginText {
+		add(formScoreLoginText, "文本含登录字样")
+	}
+	if c.HasSearch && c.PasswordCount == 0 {
+		add(formPenaltySearch, "搜索表单")
+	}
+	if c.PasswordCount > 1 {
+		add(formPenaltyMultiPass, fmt.Sprintf("%d个密码框（注册或改密表单）", c.PasswordCount))
+	}
+	c.Score = clampScore(c.Score)
+}
+
+// bestFormCandidate 返回得分最高且达到阈值的候选
+func bestFormCandidate(candidates []FormCandidate) *FormCandidate {
+	if len(candidates) == 0 || candidates[0].Score < formCandidateThreshold {
+		return nil
+	}
+	return &candidates[0]
+}
+
+// nonNil 把nil切片转换为空切片，避免传给页面脚本时变成null
+func nonNil(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	return list
+}
@@ -0,0 +1,242 @@
+package detector
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/chromedp"
+)
+
+// 身份提供方产品
+const (
+	IdPCAS      = "CAS"
+	IdPKeycloak = "Keycloak"
+	IdPADFS     = "ADFS"
+	IdPOkta     = "Okta"
+	IdPAzureAD  = "Azure AD"
+)
+
+// IdentityProvider 目标登录所依赖的统一认证服务（SSO/IdP）
+type IdentityProvider struct {
+	Product  string   `json:"product"`
+	Host     string   `json:"host"`
+	Realm    string   `json:"realm,omitempty"` // Keycloak的realm、Azure AD的租户、CAS的部署路径
+	LoginURL string   `json:"login_url"`       // 识别出IdP的地址
+	Key      string   `json:"key"`             // 去重键，同一个IdP实例相同
+	Evidence []string `json:"evidence"`
+}
+
+// idpURLRule 按地址识别IdP的规则，realm为路径正则中的第一个分组
+type idpURLRule struct {
+	product string
+	host    *regexp.Regexp
+	path    *regexp.Regexp
+	query   string // 非空时要求地址包含该查询参数
+}
+
+// azureLoginHost Azure AD（含国际版、政府云和世纪互联）的登录域名
+var azureLoginHost = regexp.MustCompile(`^login\.(microsoftonline\.com|windows\.net|microsoftonline\.us|partner\.microsoftonline\.cn|chinacloudapi\.cn)$`)
+
+var idpURLRules = []idpURLRule{
+	{product: IdPAzureAD, host: azureLoginHost, path: regexp.MustCompile(`^/([^/]+)/(?:oauth2|saml2|wsfed|login)`)},
+	{product: IdPAzureAD, host: azureLoginHost},
+	{product: IdPOkta, host: regexp.MustCompile(`\.(okta|oktapreview|okta-emea|okta-gov)\.com$`)},
+	{product: IdPADFS, path: regexp.MustCompile(`(?i)^/adfs/(?:ls|oauth2)`)},
+	{product: IdPKeycloak, path: regexp.MustCompile(`^(?:/auth)?/realms/([^/]+)/(?:protocol|login-actions)/`)},
+	{product: IdPCAS, path: regexp.MustCompile(`^(/[^?]*?)?/cas/login`)},
+	{product: IdPCAS, path: regexp.MustCompile(`^(/[^?]*?)?/login$`), query: "service"},
+}
+
+// idpDOMRules 按登录页面特征元素识别自定义域名下的IdP
+var idpDOMRules = []struct {
+	product  string
+	selector string
+}{
+	{IdPKeycloak, `#kc-form-login`},
+	{IdPKeycloak, `#kc-login`},
+	{IdPCAS, `form#fm1 input[name="execution"]`},
+	{IdPCAS, `input[name="lt"][type="hidden"]`},
+	{IdPADFS, `form#loginForm[action*="/adfs/"]`},
+	{IdPADFS, `form:has(#userNameInput):has(#passwordInput):has(#submitButton)`}, // 其他系统也可能使用userNameInput，需同时具备ADFS表单的三个元素
+	{IdPOkta, `#okta-sign-in`},
+	{IdPAzureAD, `input[name="loginfmt"]`},
+}
+
+// FingerprintIdPURL 根据地址识别IdP产品，不匹配时返回nil
+func FingerprintIdPURL(rawURL string) *IdentityProvider {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return nil
+	}
+	host := strings.ToLower(u.Hostname())
+
+	for _, rule := range idpURLRules {
+		if rule.host != nil && !rule.host.MatchString(host) {
+			continue
+		}
+		realm := ""
+		if rule.path != nil {
+			m := rule.path.FindStringSubmatch(u.Path)
+			if m == nil {
+				continue
+			}
+			if len(m) > 1 {
+				realm = strings.Trim(m[1], "/")
+			}
+		}
+		if rule.query != "" && u.Query().Get(rule.query) == "" {
+			continue
+		}
+
+		return newIdentityProvider(rule.product, host, realm, rawURL, "URL: "+rawURL)
+	}
+	return nil
+}
+
+// newIdentityProvider 创建IdP记录并计算去重键
+func newIdentityProvider(product, host, realm, loginURL, evidence string) *IdentityProvider {
+	key := strings.ToLower(product) + "://" + host
+	if realm != "" {
+		key += "/" + realm
+	}
+	return &IdentityProvider{
+		Product:  product,
+		Host:     host,
+		Realm:    realm,
+		LoginURL: loginURL,
+		Key:      key,
+		Evidence: []string{evidence},
+	}
+}
+
+// DetectIdentityProvider 根据导航重定向链、当前地址和登录页面特征识别目标使用的IdP
+func (pd *PageDetector) DetectIdentityProvider() *IdentityProvider {
+	currentURL, err := pd.browser.GetCurrentURL()
+	if err != nil {
+		return nil
+	}
+
+	// 优先使用最终落地的页面，再沿重定向链向前查找
+	urls := []string{currentURL}
+	hops := pd.browser.NavigationLog()
+	for i := len(hops) - 1; i >= 0; i-- {
+		urls = append(urls, hops[i].URL)
+	}
+
+	var idp *IdentityProvider
+	for _, u := range urls {
+		if idp = FingerprintIdPURL(u); idp != nil {
+			break
+		}
+	}
+
+	// 自定义域名部署的IdP通过页面特征识别
+	if idp == nil {
+		ctx, cancel := context.WithTimeout(pd.browser.GetContext(), pd.elementDetectTimeout)
+		defer cancel()
+		for _, rule := range idpDOMRules {
+			var nodes []*cdp.Node
+			if err := chromedp.Run(ctx, chromedp.Nodes(rule.selector, &nodes, chromedp.ByQuery, chromedp.AtLeast(0))); err == nil && len(nodes) > 0 {
+				host := ""
+				if u, err := url.Parse(currentURL); err == nil {
+					host = strings.ToLower(u.Hostname())
+				}
+				idp = newIdentityProvider(rule.product, host, "", currentURL, "页面特征: "+rule.selector)
+				break
+			}
+		}
+	}
+
+	if idp == nil {
+		return nil
+	}
+	if len(hops) > 1 {
+		chain := make([]string, 0, len(hops))
+		for _, hop := range hops {
+			chain = append(chain, hop.URL)
+		}
+		idp.Evidence = append(idp.Evidence, "重定向链: "+strings.Join(chain, " -> "))
+	}
+	return idp
+}
+
+// IdPRegistry 记录"应用通过哪个IdP认证"，用于多个目标共用同一IdP时去重。
+// 登记只表示见过该关系，只有完成爆破测试的应用才会让共用该IdP的其他应用跳过
+type IdPRegistry struct {
+	mu     sync.Mutex
+	idps   map[string]*IdentityProvider
+	apps   map[string][]string
+	tested map[string]string // IdP去重键 -> 完成测试的应用
+	order  []string
+}
+
+// NewIdPRegistry 创建IdP登记表
+func NewIdPRegistry() *IdPRegistry {
+	return &IdPRegistry{
+		idps:   make(map[string]*IdentityProvider),
+		apps:   make(map[string][]string),
+		tested: make(map[string]string),
+	}
+}
+
+// Register 登记应用与IdP的关系，返回已完成该IdP测试的其他应用，没有时返回空字符串
+func (r *IdPRegistry) Register(appURL string, idp *IdentityProvider) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	apps, seen := r.apps[idp.Key]
+	if !seen {
+		r.idps[idp.Key] = idp
+		r.order = append(r.order, idp.Key)
+	}
+
+	registered := false
+	for _, app := range apps {
+		if app == appURL {
+			registered = true
+			break
+		}
+	}
+	if !registered {
+		r.apps[idp.Key] = append(apps, appURL)
+	}
+
+	if tested := r.tested[idp.Key]; tested != appURL {
+		return tested
+	}
+	return ""
+}
+
+// MarkTested 记录应用已完成对该IdP的爆破测试，只记录第一个完成测试的应用
+func (r *IdPRegistry) MarkTested(appURL string, idp *IdentityProvider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.tested[idp.Key]; !ok {
+		r.tested[idp.Key] = appURL
+	}
+}
+
+// IdPApps 一个IdP及通过它认证的应用
+type IdPApps struct {
+	IdP    *IdentityProvider `json:"idp"`
+	Apps   []string          `json:"apps"`
+	Tested string            `json:"tested,omitempty"` // 完成测试的应用
+}
+
+// Relations 按首次出现顺序返回所有IdP及其应用
+func (r *IdPRegistry) Relations() []IdPApps {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	relations := make([]IdPApps, 0, len(r.order))
+	for _, key := range r.order {
+		apps := append([]string(nil), r.apps[key]...)
+		relations = append(relations, IdPApps{IdP: r.idps[key], Apps: apps, Tested: r.tested[key]})
+	}
+	return relations
+}
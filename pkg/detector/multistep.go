@@ -0,0 +1,118 @@
+package detector
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cyberspacesec/chrome_auto_login/pkg/browser"
+)
+
+// 分步登录的步骤动作
+const (
+	StepFillUsername = "fill_username" // 填写用户名
+	StepClickNext    = "click_next"    // 点击"下一步"
+	StepWaitPassword = "wait_password" // 等待密码框出现
+	StepFillPassword = "fill_password" // 填写密码
+	StepSubmit       = "submit"        // 提交
+)
+
+// LoginStep 分步登录中的一步
+type LoginStep struct {
+	Action   string `json:"action"`
+	Selector string `json:"selector,omitempty"`
+}
+
+// markMultiStep 页面只有用户名输入框时识别为先用户名后密码的分步登录，
+// 第一步的提交按钮作为"下一步"，密码框和真正的提交按钮在第二步再识别
+func (pd *PageDetector) markMultiStep(elements *LoginFormElements) {
+	if elements.UsernameSelector == "" || elements.PasswordSelector != "" {
+		return
+	}
+
+	next := elements.SubmitSelector
+	if selector := pd.findInForm(elements.FormSelector, pd.config.GetNextSelectors()); selector != "" {
+		next = selector
+	}
+	if next == "" {
+		return
+	}
+
+	elements.MultiStep = true
+	elements.NextSelector = next
+	elements.SubmitSelector = ""
+	pd.logger.Infof("🪜 识别为分步登录：先填写用户名，点击 %s 后再填写密码", next)
+}
+
+// WaitPasswordStep 分步登录点击"下一步"后等待密码框出现，并识别第二步的密码框和提交按钮。
+// 已记录步骤时优先使用记录的选择器，首次识别成功后记录完整的步骤序列
+func (pd *PageDetector) WaitPasswordStep(elements *LoginFormElements, timeout time.Duration) error {
+	passwordSelectors := pd.config.GetPasswordSelectors()
+	if elements.PasswordSelector != "" {
+		passwordSelectors = append([]string{elements.PasswordSelector}, passwordSelectors...)
+	}
+
+	deadline := time.Now().Add(timeout)
+	var password *browser.ElementRef
+	for {
+		ref, err := pd.browser.FindVisibleElement(passwordSelectors)
+		if err == nil && ref != nil {
+			password = ref
+			break
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("等待 %v 后仍未出现密码输入框", timeout)
+		}
+		time.Sleep(300 * time.Millisecond)
+	}
+
+	submit := pd.passwordStepSubmit(elements.SubmitSelector, password.Selector)
+	if submit == nil {
+		return fmt.Errorf("第二步未找到提交按钮")
+	}
+
+	recorded := len(elements.Steps) > 0
+	elements.PasswordSelector, elements.PasswordRef = password.Selector, password
+	elements.SubmitSelector, elements.SubmitRef = submit.Selector, submit
+
+	if !recorded {
+		elements.Steps = []LoginStep{
+			{Action: StepFillUsername, Selector: elements.UsernameSelector},
+			{Action: StepClickNext, Selector: elements.NextSelector},
+			{Action: StepWaitPassword, Selector: elements.PasswordSelector},
+			{Action: StepFillPassword, Selector: elements.PasswordSelector},
+			{Action: StepSubmit, Selector: elements.SubmitSelector},
+		}
+		pd.logger.Infof("📝 已记录分步登录流程: 密码框 %s，提交按钮 %s", elements.PasswordSelector, elements.SubmitSelector)
+	}
+	return nil
+}
+
+// passwordStepSubmit 查找第二步的提交按钮：先用已记录的选择器，再取包含密码框的表单候选，最后按全局规则查找
+func (pd *PageDetector) passwordStepSubmit(recorded, passwordSelector string) *browser.ElementRef {
+	if recorded != "" {
+		if ref, err := pd.browser.FindVisibleElement([]string{recorded}); err == nil && ref != nil {
+			return ref
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(pd.browser.GetContext(), pd.elementDetectTimeout)
+	defer cancel()
+	if candidates, err := pd.FindFormCandidates(ctx); err == nil {
+		for _, candidate := range candidates {
+			if candidate.PasswordSelector == "" || candidate.SubmitSelector == "" {
+				continue
+			}
+			if ref, err := pd.browser.ResolveElement(candidate.SubmitSelector); err == nil {
+				return ref
+			}
+		}
+	}
+
+	selectors := append(append([]string{}, pd.config.GetSubmitSelectors()...), pd.config.GetNextSelectors()...)
+	if ref, err := pd.browser.FindVisibleElement(selectors); err == nil && ref != nil {
+		return ref
+	}
+	pd.logger.Debugf("密码框 %s 附近未找到提交按钮", passwordSelector)
+	return nil
+}
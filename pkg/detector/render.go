@@ -0,0 +1,106 @@
+package detector
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/chromedp/chromedp"
+
+	"github.com/cyberspacesec/chrome_auto_login/pkg/browser"
+)
+
+// waitForRender 按配置等待单页应用完成客户端渲染，未启用时返回nil
+func (pd *PageDetector) waitForRender() *browser.RenderState {
+	wait := &pd.config.LoginPageDetection.RenderWait
+	if !wait.Enabled {
+		return nil
+	}
+
+	state, err := pd.browser.WaitForRender(wait.Quiet(), wait.Ceiling())
+	if err != nil {
+		// 等待期间发生整页跳转会销毁执行上下文，不影响后续检测
+		pd.logger.Debugf("%v", err)
+		return nil
+	}
+	if state.Reason == browser.RenderTimeout {
+		pd.logger.Debugf("⏳ 页面在 %v 内未稳定（DOM变化 %d 次），按当前状态检测", wait.Ceiling(), state.Mutations)
+	}
+	return state
+}
+
+// evaluateLoginPage 读取当前页面的标题、地址和正文并计算登录页面得分
+func (pd *PageDetector) evaluateLoginPage() (*ScoreBreakdown, error) {
+	ctx, cancel := context.WithTimeout(pd.browser.GetContext(), pd.analysisTimeout)
+	defer cancel()
+
+	var title, url, content string
+	err := chromedp.Run(ctx,
+		chromedp.Title(&title),
+		chromedp.Location(&url),
+		chromedp.Text("body", &content),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pd.scoreLoginPage(title, url, content, ctx), nil
+}
+
+// recheckHashRoutes 判定为非登录页面后，在放弃前检查哈希路由：
+// 先等待应用自行跳转（如鉴权失败后从 #/ 跳到 #/login），再依次尝试配置的登录路由
+func (pd *PageDetector) recheckHashRoutes() *ScoreBreakdown {
+	wait := &pd.config.LoginPageDetection.RenderWait
+	if !wait.Enabled {
+		return nil
+	}
+
+	if wait.HashWait > 0 {
+		if url, err := pd.browser.WaitForHashChange(time.Duration(wait.HashWait) * time.Second); err == nil && url != "" {
+			pd.logger.Infof("🔀 页面路由已跳转到 %s，重新检测", url)
+			if breakdown := pd.recheck(); breakdown != nil && breakdown.IsLogin {
+				return breakdown
+			}
+		}
+	}
+
+	current, err := pd.browser.GetCurrentURL()
+	if err != nil || !isHashRouted(current) {
+		return nil
+	}
+	original := current[strings.Index(current, "#"):]
+
+	for _, route := range wait.HashRoutes {
+		if route == original {
+			continue
+		}
+		pd.logger.Debugf("尝试哈希路由: %s", route)
+		if err := pd.browser.SetHash(route); err != nil {
+			pd.logger.Debugf("切换哈希路由失败: %v", err)
+			break
+		}
+		if breakdown := pd.recheck(); breakdown != nil && breakdown.IsLogin {
+			pd.logger.Infof("🔀 哈希路由 %s 为登录页面", route)
+			return breakdown
+		}
+	}
+
+	// 都不是登录页面时回到原路由
+	_ = pd.browser.SetHash(original)
+	return nil
+}
+
+// recheck 等待渲染后重新计算得分
+func (pd *PageDetector) recheck() *ScoreBreakdown {
+	pd.waitForRender()
+	breakdown, err := pd.evaluateLoginPage()
+	if err != nil {
+		pd.logger.Debugf("重新检测失败: %v", err)
+		return nil
+	}
+	return breakdown
+}
+
+// isHashRouted 地址是否使用哈希路由（#/ 或 #!/）
+func isHashRouted(url string) bool {
+	return strings.Contains(url, "#/") || strings.Contains(url, "#!/")
+}
@@ -0,0 +1,163 @@
+package detector
+
+import (
+	"context"
+	"regexp"
+	"strings"
+)
+
+// 登录页面打分的特征类别
+const (
+	SignalTitle   = "title"
+	SignalURL     = "url"
+	SignalContent = "content"
+	SignalForm    = "form"
+)
+
+// SignalMatch 一条命中的检测规则
+type SignalMatch struct {
+	Rule  string  `json:"rule"`  // 命中的关键词、正则或选择器
+	Score float64 `json:"score"` // 该规则的分值
+}
+
+// CategoryScore 单类特征的得分
+type CategoryScore struct {
+	Category     string        `json:"category"`
+	Score        float64       `json:"score"`        // 命中规则分值之和（限制在0~1）
+	Weight       float64       `json:"weight"`       // 该类特征的权重
+	Contribution float64       `json:"contribution"` // 对置信度的贡献 = Score × Weight
+	Matches      []SignalMatch `json:"matches"`
+}
+
+// ScoreBreakdown 登录页面判定的得分明细
+type ScoreBreakdown struct {
+	Categories []CategoryScore `json:"categories"`
+	Confidence float64         `json:"confidence"`
+	Threshold  float64         `json:"threshold"`
+	ConfigRule string          `json:"config_rule,omitempty"` // Config.IsLoginPage中命中的规则
+	IsLogin    bool            `json:"is_login"`
+}
+
+// Category 获取指定类别的得分
+func (s *ScoreBreakdown) Category(category string) *CategoryScore {
+	for i := range s.Categories {
+		if s.Categories[i].Category == category {
+			return &s.Categories[i]
+		}
+	}
+	return nil
+}
+
+// scoreLoginPage 按login_page_detection配置计算登录页面得分明细
+func (pd *PageDetector) scoreLoginPage(title, url, content string, ctx context.Context) *ScoreBreakdown {
+	detection := &pd.config.LoginPageDetection
+	weights := detection.SignalWeights()
+
+	breakdown := &ScoreBreakdown{
+		Categories: []CategoryScore{
+			newCategoryScore(SignalTitle, weights.Title, pd.checkTitleFeatures(title)),
+			newCategoryScore(SignalURL, weights.URL, pd.checkURLFeatures(url)),
+			newCategoryScore(SignalContent, weights.Content, pd.checkContentFeatures(content)),
+			newCategoryScore(SignalForm, weights.Form, pd.checkFormFeatures(ctx)),
+		},
+		Threshold: detection.LoginThreshold(),
+	}
+	for _, category := range breakdown.Categories {
+		breakdown.Confidence += category.Contribution
+	}
+
+	breakdown.ConfigRule, breakdown.IsLogin = pd.config.MatchLoginRule(title, url, content)
+	if breakdown.Confidence >= breakdown.Threshold {
+		breakdown.IsLogin = true
+	}
+	return breakdown
+}
+
+// newCategoryScore 汇总单类特征的命中规则
+func newCategoryScore(category string, weight float64, matches []SignalMatch) CategoryScore {
+	score := 0.0
+	for _, match := range matches {
+		score += match.Score
+	}
+	score = clampScore(score)
+
+	if matches == nil {
+		matches = []SignalMatch{}
+	}
+	return CategoryScore{
+		Category:     category,
+		Score:        score,
+		Weight:       weight,
+		Contribution: score * weight,
+		Matches:      matches,
+	}
+}
+
+// checkTitleFeatures 检查标题特征
+func (pd *PageDetector) checkTitleFeatures(title string) []SignalMatch {
+	detection := &pd.config.LoginPageDetection
+	title = strings.ToLower(title)
+
+	var matches []SignalMatch
+	for _, rule := range detection.TitleKeywords {
+		if rule.Pattern != "" && strings.Contains(title, strings.ToLower(rule.Pattern)) {
+			matches = append(matches, SignalMatch{Rule: rule.Pattern, Score: detection.RuleScore(rule)})
+		}
+	}
+	return matches
+}
+
+// checkURLFeatures 检查URL特征
+func (pd *PageDetector) checkURLFeatures(url string) []SignalMatch {
+	detection := &pd.config.LoginPageDetection
+
+	var matches []SignalMatch
+	for _, rule := range detection.URLPatterns {
+		if matched, _ := regexp.MatchString(rule.Pattern, url); matched {
+			matches = append(matches, SignalMatch{Rule: rule.Pattern, Score: detection.RuleScore(rule)})
+		}
+	}
+	return matches
+}
+
+// checkContentFeatures 检查内容特征
+func (pd *PageDetector) checkContentFeatures(content string) []SignalMatch {
+	detection := &pd.config.LoginPageDetection
+	content = strings.ToLower(content)
+
+	var matches []SignalMatch
+	for _, rule := range detection.ContentKeywords {
+		if rule.Pattern != "" && strings.Contains(content, strings.ToLower(rule.Pattern)) {
+			matches = append(matches, SignalMatch{Rule: rule.Pattern, Score: detection.RuleScore(rule)})
+		}
+	}
+	return matches
+}
+
+// checkFormFeatures 检查表单特征，记录命中的选择器
+func (pd *PageDetector) checkFormFeatures(ctx context.Context) []SignalMatch {
+	scores := pd.config.LoginPageDetection.ElementScores()
+
+	var matches []SignalMatch
+	if selector := pd.findExistingSelector(ctx, pd.config.GetUsernameSelectors()); selector != "" {
+		matches = append(matches, SignalMatch{Rule: "username: " + selector, Score: scores.Username})
+	}
+	if selector := pd.findExistingSelector(ctx, pd.config.GetPasswordSelectors()); selector != "" {
+		matches = append(matches, SignalMatch{Rule: "password: " + selector, Score: scores.Password})
+	}
+	if selector := pd.findExistingSelector(ctx, pd.config.GetSubmitSelectors()); selector != "" {
+		matches = append(matches, SignalMatch{Rule: "submit: " + selector, Score: scores.Submit})
+	}
+	return matches
+}
+
+// clampScore 将单项特征得分限制在0~1之间
+func clampScore(score float64) float64 {
+	if score > 1.0 {
+		return 1.0
+	}
+	if score < 0 {
+		return 0
+	}
+	return score
+}
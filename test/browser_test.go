@@ -0,0 +1,228 @@
+package test
+
+import (
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/chromedp/chromedp"
+
+	"github.com/cyberspacesec/chrome_auto_login/pkg/browser"
+	"github.com/cyberspacesec/chrome_auto_login/pkg/config"
+	"github.com/cyberspacesec/chrome_auto_login/util"
+)
+
+// startTestBrowser 启动用于测试的无头浏览器
+func startTestBrowser(t *testing.T) *browser.Browser {
+	t.Helper()
+
+	// 屏蔽Chrome的错误日志
+	log.SetOutput(os.Stdout)
+
+	// 加载配置
+	cfg, err := config.LoadConfig("../config/config.yaml")
+	if err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+	cfg.Browser.Headless = true
+
+	if err := util.InitLogger(util.LogConfig{Level: "error"}); err != nil {
+		t.Fatalf("初始化日志失败: %v", err)
+	}
+
+	// 启动浏览器
+	browserInstance := browser.NewBrowser(cfg, util.Logger)
+	if err := browserInstance.Start(); err != nil {
+		t.Fatalf("启动浏览器失败: %v", err)
+	}
+	return browserInstance
+}
+
+// TestFillInputSpecialCharacters 测试特殊字符密码能被原样填入输入框
+func TestFillInputSpecialCharacters(t *testing.T) {
+	if testing.Short() {
+		t.Skip("跳过输入框填充测试（使用 -short 标志）")
+	}
+
+	browserInstance := startTestBrowser(t)
+	defer browserInstance.Close()
+
+	page := `data:text/html,<html><body><input id="pwd" type="text"></body></html>`
+	if err := browserInstance.NavigateTo(page); err != nil {
+		t.Fatalf("打开测试页面失败: %v", err)
+	}
+
+	testCases := []struct {
+		name  string
+		value string
+	}{
+		{"反引号和模板字符串", "pa`ss${alert(1)}`"},
+		{"脚本结束标签", "</script><script>alert(1)</script>"},
+		{"引号和反斜杠", `a'b"c\d\\e`},
+		{"Unicode行分隔符", "line\u2028sep\u2029para"},
+		{"中文和表情", "密码🔐测试"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := browserInstance.FillInput("#pwd", tc.value); err != nil {
+				t.Fatalf("填充输入框失败: %v", err)
+			}
+
+			value, err := browserInstance.GetInputValue("#pwd")
+			if err != nil {
+				t.Fatalf("获取输入框值失败: %v", err)
+			}
+			if value != tc.value {
+				t.Errorf("输入值不一致: 期望=%q, 实际=%q", tc.value, value)
+			}
+		})
+	}
+}
+
+// TestConsoleMessages 测试控制台输出和未捕获异常的收集
+func TestConsoleMessages(t *testing.T) {
+	if testing.Short() {
+		t.Skip("跳过控制台消息测试（使用 -short 标志）")
+	}
+
+	browserInstance := startTestBrowser(t)
+	defer browserInstance.Close()
+
+	page := `data:text/html,<html><body><script>console.log("hello", 42); console.error("csrf token missing"); null.submit();</script></body></html>`
+	if err := browserInstance.NavigateTo(page); err != nil {
+		t.Fatalf("打开测试页面失败: %v", err)
+	}
+
+	messages := browserInstance.ConsoleMessages()
+	if len(messages) != 3 {
+		t.Fatalf("控制台消息数量不正确: 期望=3, 实际=%d (%v)", len(messages), messages)
+	}
+	if messages[0].Level != "log" || messages[0].Text != "hello 42" {
+		t.Errorf("console.log记录不正确: %v", messages[0])
+	}
+	if messages[1].Level != "error" || messages[1].Text != "csrf token missing" {
+		t.Errorf("console.error记录不正确: %v", messages[1])
+	}
+	if messages[2].Level != "exception" || !strings.Contains(messages[2].Text, "TypeError") {
+		t.Errorf("未捕获异常记录不正确: %v", messages[2])
+	}
+
+	if errors := browserInstance.ConsoleErrors(); len(errors) != 2 {
+		t.Errorf("错误级别消息数量不正确: 期望=2, 实际=%d", len(errors))
+	}
+
+	// 开始新的尝试时清空记录
+	browserInstance.BeginAttempt()
+	if messages := browserInstance.ConsoleMessages(); len(messages) != 0 {
+		t.Errorf("开始新尝试后控制台消息未清空: %v", messages)
+	}
+}
+
+// TestResolveElement 测试匹配规则解析为唯一选择器，并在DOM变化后按节点ID重新定位
+func TestResolveElement(t *testing.T) {
+	if testing.Short() {
+		t.Skip("跳过元素解析测试（使用 -short 标志）")
+	}
+
+	browserInstance := startTestBrowser(t)
+	defer browserInstance.Close()
+
+	page := `data:text/html,<html><body><div><input type="text" class="q"></div>` +
+		`<form><input type="text" name="user"><input type="password"></form>` +
+		`<form><input type="text" name="user"><input type="password" id="pwd"></form></body></html>`
+	if err := browserInstance.NavigateTo(page); err != nil {
+		t.Fatalf("打开测试页面失败: %v", err)
+	}
+
+	testCases := []struct {
+		name     string
+		selector string
+		expected string
+	}{
+		{"唯一id", `form:last-of-type input[type="password"]`, "#pwd"},
+		{"表单内name", `form input[name="user"]`, `html > body:nth-of-type(1) > form:nth-of-type(1) input[name="user"]`},
+		{"CSS路径", `input[type="text"]`, "html > body:nth-of-type(1) > div:nth-of-type(1) > input:nth-of-type(1)"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ref, err := browserInstance.ResolveElement(tc.selector)
+			if err != nil {
+				t.Fatalf("解析元素失败: %v", err)
+			}
+			if ref.Selector != tc.expected {
+				t.Errorf("唯一选择器不正确: 期望=%s, 实际=%s", tc.expected, ref.Selector)
+			}
+			if ref.BackendNodeID == 0 {
+				t.Error("未记录后端节点ID")
+			}
+		})
+	}
+
+	// 在前面插入同级元素后，原CSS路径指向了其他节点
+	ref, err := browserInstance.ResolveElement(`input.q`)
+	if err != nil {
+		t.Fatalf("解析元素失败: %v", err)
+	}
+	if err := chromedp.Run(browserInstance.GetContext(), chromedp.Evaluate(`document.querySelector('div').prepend(document.createElement('input'))`, nil)); err != nil {
+		t.Fatalf("修改页面失败: %v", err)
+	}
+	if selector := browserInstance.Locate(ref); selector != "html > body:nth-of-type(1) > div:nth-of-type(1) > input:nth-of-type(2)" {
+		t.Errorf("重新定位结果不正确: %s", selector)
+	}
+}
+
+// TestHostResolverValidation 测试解析覆盖的主机名和IP中不能夹带额外规则
+func TestHostResolverValidation(t *testing.T) {
+	browserInstance := browser.NewBrowser(&config.Config{}, util.Logger)
+
+	testCases := []struct {
+		resolve map[string]string
+		valid   bool
+	}{
+		{map[string]string{"www.example.com": "10.0.0.12", "*.example.com": "::1"}, true},
+		{map[string]string{"www.example.com": "10.0.0.12, MAP * 6.6.6.6"}, false},
+		{map[string]string{"www.example.com,MAP *": "10.0.0.12"}, false},
+		{map[string]string{"www.example.com 6.6.6.6": "10.0.0.12"}, false},
+		{map[string]string{"www.example.com": "staging"}, false},
+	}
+
+	for _, tc := range testCases {
+		err := browserInstance.SetHostResolver(tc.resolve)
+		if (err == nil) != tc.valid {
+			t.Errorf("%v: 期望有效=%v, 错误=%v", tc.resolve, tc.valid, err)
+		}
+	}
+}
+
+// TestApplyEmulationRollback 测试首次应用的配置档部分生效后失败时恢复默认设置
+func TestApplyEmulationRollback(t *testing.T) {
+	if testing.Short() {
+		t.Skip("跳过模拟配置回滚测试（使用 -short 标志）")
+	}
+
+	browserInstance := startTestBrowser(t)
+	defer browserInstance.Close()
+
+	if err := browserInstance.NavigateTo(`data:text/html,<html><body></body></html>`); err != nil {
+		t.Fatalf("打开测试页面失败: %v", err)
+	}
+
+	// 视口先于时区生效，无效的时区使应用失败
+	profile := &config.EmulationProfile{Width: 321, Height: 480, Timezone: "Invalid/Zone"}
+	if err := browserInstance.ApplyEmulation("broken", profile); err == nil {
+		t.Fatal("无效的时区应使应用配置档失败")
+	}
+	if name := browserInstance.EmulationProfile(); name != "" {
+		t.Errorf("失败后配置档名称应为空: %s", name)
+	}
+
+	var width int
+	if err := chromedp.Run(browserInstance.GetContext(), chromedp.Evaluate(`window.innerWidth`, &width)); err != nil {
+		t.Fatalf("获取视口宽度失败: %v", err)
+	}
+	if width == 321 {
+		t.Error("失败后视口覆盖未清除")
+	}
+}
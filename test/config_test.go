@@ -0,0 +1,122 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cyberspacesec/chrome_auto_login/pkg/config"
+)
+
+// loadTestConfig 把YAML内容写入临时文件并加载
+func loadTestConfig(t *testing.T, content string) (*config.Config, error) {
+	filename := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+		t.Fatalf("写入配置文件失败: %v", err)
+	}
+	return config.LoadConfig(filename)
+}
+
+// TestLegacyDetectionConfig 测试没有weights、default_score和form_scores的旧配置仍能识别登录页面
+func TestLegacyDetectionConfig(t *testing.T) {
+	cfg, err := loadTestConfig(t, `
+login_page_detection:
+  url_patterns:
+    - "(?i).*login.*"
+  content_keywords:
+    - "密码"
+    - { pattern: "注册", score: -0.05 }
+`)
+	if err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+	detection := &cfg.LoginPageDetection
+
+	weights := detection.SignalWeights()
+	if weights.Title != 0.3 || weights.URL != 0.2 || weights.Content != 0.25 || weights.Form != 0.25 {
+		t.Errorf("默认权重不正确: %+v", weights)
+	}
+	scores := detection.ElementScores()
+	if scores.Username != 0.4 || scores.Password != 0.4 || scores.Submit != 0.2 {
+		t.Errorf("默认表单分值不正确: %+v", scores)
+	}
+	if score := detection.RuleScore(detection.ContentKeywords[0]); score != 0.2 {
+		t.Errorf("字符串规则默认分值不正确: %v", score)
+	}
+
+	if rule, ok := cfg.MatchLoginRule("", "https://login.example.com/", ""); !ok || rule != "url_patterns: (?i).*login.*" {
+		t.Errorf("旧的URL规则应判定为登录页面: %s %v", rule, ok)
+	}
+	if _, ok := cfg.MatchLoginRule("", "https://example.com/", "请输入密码"); !ok {
+		t.Error("旧的内容关键词应判定为登录页面")
+	}
+	if rule, ok := cfg.MatchLoginRule("", "https://example.com/", "用户注册"); ok {
+		t.Errorf("负分规则不应判定为登录页面: %s", rule)
+	}
+}
+
+// TestInvalidTargetMatch 测试加载配置时报告无效的targets[].match正则
+func TestInvalidTargetMatch(t *testing.T) {
+	_, err := loadTestConfig(t, `
+targets:
+  - match: "example\\.com"
+    profile: mobile
+  - match: "oa\\.(corp"
+    profile: mobile
+`)
+	if err == nil || !strings.Contains(err.Error(), "targets[1].match") {
+		t.Errorf("应报告无效的正则表达式: %v", err)
+	}
+}
+
+// TestMaxCrashRestarts 测试max_crash_restarts为0时不重启，未配置或为负数时使用默认值
+func TestMaxCrashRestarts(t *testing.T) {
+	testCases := []struct {
+		content string
+		want    int
+	}{
+		{"browser: {}", 2},
+		{"browser:\n  max_crash_restarts: 0", 0},
+		{"browser:\n  max_crash_restarts: -1", 2},
+		{"browser:\n  max_crash_restarts: 5", 5},
+	}
+
+	for _, tc := range testCases {
+		cfg, err := loadTestConfig(t, tc.content)
+		if err != nil {
+			t.Fatalf("加载配置失败: %v", err)
+		}
+		if got := cfg.GetMaxCrashRestarts(); got != tc.want {
+			t.Errorf("%q: 期望=%d, 实际=%d", tc.content, tc.want, got)
+		}
+	}
+}
+
+// TestInvalidResetStrategy 测试加载配置时报告未知的页面重置策略
+func TestInvalidResetStrategy(t *testing.T) {
+	testCases := []struct {
+		content string
+		field   string
+	}{
+		{"bruteforce:\n  reset_strategy: reload_every", "bruteforce.reset_strategy"},
+		{"targets:\n  - match: \"example\\\\.com\"\n    reset: Reload", "targets[0].reset"},
+	}
+
+	for _, tc := range testCases {
+		if _, err := loadTestConfig(t, tc.content); err == nil || !strings.Contains(err.Error(), tc.field) {
+			t.Errorf("应报告无效的%s: %v", tc.field, err)
+		}
+	}
+
+	cfg, err := loadTestConfig(t, "targets:\n  - match: \"example\\\\.com\"\n    reset: reload")
+	if err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+	if strategy, _ := cfg.GetResetStrategy("https://other.com/"); strategy != config.ResetInPlace {
+		t.Errorf("未配置时应使用原地重置: %s", strategy)
+	}
+	if strategy, _ := cfg.GetResetStrategy("https://example.com/"); strategy != config.ResetReload {
+		t.Errorf("目标配置的重置策略未生效: %s", strategy)
+	}
+}
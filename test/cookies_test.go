@@ -0,0 +1,77 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chromedp/cdproto/network"
+
+	"github.com/cyberspacesec/chrome_auto_login/pkg/browser"
+)
+
+// sampleCookies 测试用Cookie
+func sampleCookies() []*network.Cookie {
+	return []*network.Cookie{
+		{Name: "JSESSIONID", Value: "abc123", Domain: "portal.example.com", Path: "/", HTTPOnly: true, Secure: true, Session: true},
+		{Name: "lang", Value: "zh-CN", Domain: ".example.com", Path: "/", Expires: 1893456000},
+		{Name: "admin_token", Value: "t0k", Domain: "portal.example.com", Path: "/admin"},
+		{Name: "other", Value: "x", Domain: "other.com", Path: "/"},
+	}
+}
+
+// TestNetscapeCookies 测试Netscape cookies.txt格式导出
+func TestNetscapeCookies(t *testing.T) {
+	output := browser.NetscapeCookies(sampleCookies())
+
+	if !strings.HasPrefix(output, "# Netscape HTTP Cookie File") {
+		t.Errorf("缺少Netscape文件头: %q", output)
+	}
+
+	expected := []string{
+		"#HttpOnly_portal.example.com\tFALSE\t/\tTRUE\t0\tJSESSIONID\tabc123",
+		".example.com\tTRUE\t/\tFALSE\t1893456000\tlang\tzh-CN",
+		"portal.example.com\tFALSE\t/admin\tFALSE\t0\tadmin_token\tt0k",
+	}
+	for _, line := range expected {
+		if !strings.Contains(output, line+"\n") {
+			t.Errorf("缺少Cookie行: %q", line)
+		}
+	}
+}
+
+// TestCookieHeader 测试按URL匹配生成Cookie请求头
+func TestCookieHeader(t *testing.T) {
+	testCases := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{"HTTPS根路径", "https://portal.example.com/", "JSESSIONID=abc123; lang=zh-CN"},
+		{"子路径优先", "https://portal.example.com/admin/users", "admin_token=t0k; JSESSIONID=abc123; lang=zh-CN"},
+		{"路径前缀不完整", "https://portal.example.com/administrator", "JSESSIONID=abc123; lang=zh-CN"},
+		{"HTTP不发送Secure", "http://portal.example.com/", "lang=zh-CN"},
+		{"父域Cookie", "https://www.example.com/", "lang=zh-CN"},
+		{"无关域名", "https://evil-example.com/", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := browser.CookieHeader(sampleCookies(), tc.url); got != tc.want {
+				t.Errorf("Cookie头不一致: 期望=%q, 实际=%q", tc.want, got)
+			}
+		})
+	}
+}
+
+// TestCurlCommand 测试curl命令中的shell转义
+func TestCurlCommand(t *testing.T) {
+	cookies := []*network.Cookie{
+		{Name: "q", Value: "it's", Domain: "example.com", Path: "/"},
+	}
+
+	got := browser.CurlCommand(cookies, "https://example.com/home")
+	want := `curl -b 'q=it'\''s' 'https://example.com/home'`
+	if got != want {
+		t.Errorf("curl命令不一致: 期望=%s, 实际=%s", want, got)
+	}
+}
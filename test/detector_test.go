@@ -0,0 +1,207 @@
+package test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cyberspacesec/chrome_auto_login/pkg/config"
+	"github.com/cyberspacesec/chrome_auto_login/pkg/detector"
+	"github.com/cyberspacesec/chrome_auto_login/util"
+)
+
+// TestFormCandidates 测试字段取自得分最高的登录表单，而不是页头的搜索表单
+func TestFormCandidates(t *testing.T) {
+	if testing.Short() {
+		t.Skip("跳过表单候选测试（使用 -short 标志）")
+	}
+
+	browserInstance := startTestBrowser(t)
+	defer browserInstance.Close()
+
+	page := `data:text/html,<html><body>` +
+		`<header><form action="/search"><input type="text" name="q"><button type="submit">Go</button></form></header>` +
+		`<form id="login" action="/login"><input type="text" name="username"><input type="password" name="password">` +
+		`<button type="submit">登录</button></form>` +
+		`<footer><form action="/newsletter"><input type="email" name="email"><button type="submit">Subscribe</button></form></footer>` +
+		`</body></html>`
+	if err := browserInstance.NavigateTo(page); err != nil {
+		t.Fatalf("打开测试页面失败: %v", err)
+	}
+
+	cfg, err := config.LoadConfig("../config/config.yaml")
+	if err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+	pd := detector.NewPageDetector(browserInstance, cfg, util.Logger)
+
+	elements, err := pd.DetectLoginForm()
+	if err != nil {
+		t.Fatalf("检测登录表单失败: %v", err)
+	}
+	if len(elements.Candidates) != 3 {
+		t.Fatalf("表单候选数量不正确: 期望=3, 实际=%d", len(elements.Candidates))
+	}
+	if elements.FormSelector != "#login" {
+		t.Errorf("选中的表单不正确: %s", elements.FormSelector)
+	}
+	for name, selector := range map[string]string{
+		"用户名": elements.UsernameSelector,
+		"密码":  elements.PasswordSelector,
+		"提交":  elements.SubmitSelector,
+	} {
+		if !strings.HasPrefix(selector, "#login") {
+			t.Errorf("%s选择器不属于登录表单: %s", name, selector)
+		}
+	}
+
+	// 登录表单缺少提交按钮时，不应退回到页头的搜索按钮
+	page = `data:text/html,<html><body>` +
+		`<header><form action="/search"><input type="text" name="q"><button type="submit">Go</button></form></header>` +
+		`<form id="login" action="/login"><input type="text" name="username"><input type="password" name="password"></form>` +
+		`</body></html>`
+	if err := browserInstance.NavigateTo(page); err != nil {
+		t.Fatalf("打开测试页面失败: %v", err)
+	}
+	elements, err = pd.DetectLoginForm()
+	if err != nil {
+		t.Fatalf("检测登录表单失败: %v", err)
+	}
+	if elements.FormSelector != "#login" || elements.SubmitSelector != "" {
+		t.Errorf("缺少的提交按钮应只在登录表单内查找: 表单=%s, 提交=%s", elements.FormSelector, elements.SubmitSelector)
+	}
+}
+
+// TestHoneypotFields 测试跳过隐藏的重复输入框，并标记看不见但可填写的蜜罐字段
+func TestHoneypotFields(t *testing.T) {
+	if testing.Short() {
+		t.Skip("跳过蜜罐字段测试（使用 -short 标志）")
+	}
+
+	browserInstance := startTestBrowser(t)
+	defer browserInstance.Close()
+
+	page := `data:text/html,<html><body><form id="login" action="/login">` +
+		`<input type="text" name="username" style="display:none">` +
+		`<input type="text" name="email" style="position:absolute;left:-9999px">` +
+		`<input type="text" name="website" style="opacity:0">` +
+		`<input type="text" name="username" class="real">` +
+		`<input type="password" name="password">` +
+		// Element UI风格的协议复选框：原生input透明且无尺寸，由外层label展示
+		`<label class="el-checkbox"><span class="el-checkbox__input"><span class="el-checkbox__inner"></span>` +
+		`<input type="checkbox" name="agree" style="opacity:0;position:absolute;margin:0;width:0;height:0"></span>` +
+		`<span class="el-checkbox__label">我已阅读并同意用户协议</span></label>` +
+		`<button type="submit">登录</button></form></body></html>`
+	if err := browserInstance.NavigateTo(page); err != nil {
+		t.Fatalf("打开测试页面失败: %v", err)
+	}
+
+	cfg, err := config.LoadConfig("../config/config.yaml")
+	if err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+	pd := detector.NewPageDetector(browserInstance, cfg, util.Logger)
+
+	elements, err := pd.DetectLoginForm()
+	if err != nil {
+		t.Fatalf("检测登录表单失败: %v", err)
+	}
+	if elements.UsernameSelector != "#login > input:nth-of-type(4)" {
+		t.Errorf("用户名选择器应指向可见的输入框: %s", elements.UsernameSelector)
+	}
+	if !elements.HasCheckbox {
+		t.Error("由label展示的协议复选框应被识别")
+	}
+
+	names := map[string]bool{}
+	for _, field := range elements.Honeypots {
+		names[field.Name] = true
+	}
+	if len(names) != 2 || !names["email"] || !names["website"] || names["agree"] {
+		t.Errorf("蜜罐字段识别不正确: %+v", elements.Honeypots)
+	}
+}
+
+// TestMultiStepLogin 测试识别先用户名后密码的分步登录，并记录第二步的步骤
+func TestMultiStepLogin(t *testing.T) {
+	if testing.Short() {
+		t.Skip("跳过分步登录测试（使用 -short 标志）")
+	}
+
+	browserInstance := startTestBrowser(t)
+	defer browserInstance.Close()
+
+	page := `data:text/html,<html><body><form id="login" action="/login" onsubmit="return false">` +
+		`<input type="text" name="username"><input type="password" name="passwd" style="display:none">` +
+		`<button type="button" id="next" onclick="this.remove();setTimeout(function(){` +
+		`document.querySelector('[name=passwd]').style.display='';` +
+		`var b=document.createElement('button');b.type='submit';b.textContent='登录';document.forms[0].appendChild(b)},500)">Next</button>` +
+		`</form></body></html>`
+	if err := browserInstance.NavigateTo(page); err != nil {
+		t.Fatalf("打开测试页面失败: %v", err)
+	}
+
+	cfg, err := config.LoadConfig("../config/config.yaml")
+	if err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+	pd := detector.NewPageDetector(browserInstance, cfg, util.Logger)
+
+	elements, err := pd.DetectLoginForm()
+	if err != nil {
+		t.Fatalf("检测登录表单失败: %v", err)
+	}
+	if !elements.MultiStep || elements.NextSelector != "#next" {
+		t.Fatalf("未识别为分步登录: multi_step=%t, next=%s", elements.MultiStep, elements.NextSelector)
+	}
+	if elements.PasswordSelector != "" {
+		t.Errorf("第一步不应选中隐藏的密码框: %s", elements.PasswordSelector)
+	}
+
+	if err := browserInstance.ClickElement(elements.NextSelector); err != nil {
+		t.Fatalf("点击下一步失败: %v", err)
+	}
+	if err := pd.WaitPasswordStep(elements, 5*time.Second); err != nil {
+		t.Fatalf("等待密码框失败: %v", err)
+	}
+	if elements.PasswordSelector != `input[name="passwd"]` {
+		t.Errorf("第二步密码框不正确: %s", elements.PasswordSelector)
+	}
+	if len(elements.Steps) != 5 || elements.Steps[4].Action != detector.StepSubmit || elements.Steps[4].Selector == "" {
+		t.Errorf("步骤记录不正确: %+v", elements.Steps)
+	}
+}
+
+// TestRenderWait 测试等待客户端渲染完成后再判定登录页面
+func TestRenderWait(t *testing.T) {
+	if testing.Short() {
+		t.Skip("跳过渲染等待测试（使用 -short 标志）")
+	}
+
+	browserInstance := startTestBrowser(t)
+	defer browserInstance.Close()
+
+	// 导航完成3秒后才渲染登录表单，超过NavigateTo的固定等待
+	page := `data:text/html,<html><head><title>Loading</title></head><body><div class="spinner">...</div><script>` +
+		`setTimeout(function(){document.title='用户登录';document.body.innerHTML=` +
+		`'<form action="/login"><input type="text" name="username"><input type="password" name="password">` +
+		`<button type="submit">登录</button></form>'},3000)</script></body></html>`
+	if err := browserInstance.NavigateTo(page); err != nil {
+		t.Fatalf("打开测试页面失败: %v", err)
+	}
+
+	cfg, err := config.LoadConfig("../config/config.yaml")
+	if err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+	cfg.LoginPageDetection.RenderWait.Enabled = true
+	pd := detector.NewPageDetector(browserInstance, cfg, util.Logger)
+
+	isLogin, err := pd.IsLoginPage()
+	if err != nil {
+		t.Fatalf("检测登录页面失败: %v", err)
+	}
+	if !isLogin {
+		t.Error("渲染完成后应判定为登录页面")
+	}
+}
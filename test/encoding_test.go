@@ -0,0 +1,102 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/unicode"
+
+	"github.com/cyberspacesec/chrome_auto_login/util"
+)
+
+// TestDetectEncoding 测试按BOM、响应头、meta标签和内容检测编码
+func TestDetectEncoding(t *testing.T) {
+	gbkPage, err := simplifiedchinese.GBK.NewEncoder().Bytes([]byte("<html><body>用户登录 请输入密码</body></html>"))
+	if err != nil {
+		t.Fatalf("生成GBK页面失败: %v", err)
+	}
+
+	testCases := []struct {
+		name        string
+		data        []byte
+		contentType string
+		charset     string
+		source      string
+	}{
+		{"UTF-8 BOM", []byte("\xef\xbb\xbf<html></html>"), "text/html; charset=gbk", "UTF-8", util.EncodingFromBOM},
+		{"响应头", gbkPage, "text/html; charset=gb2312", "GBK", util.EncodingFromHeader},
+		{"带引号的响应头", []byte("<html></html>"), `text/html; charset="Big5"`, "BIG5", util.EncodingFromHeader},
+		{"meta charset", []byte(`<html><head><meta charset="gbk"></head></html>`), "text/html", "GBK", util.EncodingFromMeta},
+		{"meta http-equiv", []byte(`<meta http-equiv="Content-Type" content="text/html; charset=shift_jis">`), "", "SHIFT_JIS", util.EncodingFromMeta},
+		{"未知响应头编码", []byte(`<meta charset="utf-8">`), "text/html; charset=x-unknown", "UTF-8", util.EncodingFromMeta},
+		{"内容推测GBK", gbkPage, "text/html", "GB18030", util.EncodingFromContent},
+		{"内容推测UTF-8", []byte("<html><body>用户登录</body></html>"), "", "UTF-8", util.EncodingFromContent},
+	}
+
+	for _, tc := range testCases {
+		charset, source := util.DetectEncoding(tc.data, tc.contentType)
+		if charset != tc.charset || source != tc.source {
+			t.Errorf("%s: 期望=%s(%s), 实际=%s(%s)", tc.name, tc.charset, tc.source, charset, source)
+		}
+	}
+
+	text, err := util.DecodeBytes(gbkPage, "GB18030")
+	if err != nil {
+		t.Fatalf("解码GBK页面失败: %v", err)
+	}
+	if text != "<html><body>用户登录 请输入密码</body></html>" {
+		t.Errorf("GBK页面解码结果不正确: %s", text)
+	}
+}
+
+// TestReadTextFile 测试读取非UTF-8编码的字典文件
+func TestReadTextFile(t *testing.T) {
+	const content = "管理员\r\nadmin\r\n"
+
+	gbk, err := simplifiedchinese.GBK.NewEncoder().Bytes([]byte(content))
+	if err != nil {
+		t.Fatalf("生成GBK文件失败: %v", err)
+	}
+	utf16, err := unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewEncoder().Bytes([]byte(content))
+	if err != nil {
+		t.Fatalf("生成UTF-16文件失败: %v", err)
+	}
+
+	// UTF-8字典与GBK字典拼接而成的文件
+	mixed := append([]byte("密码\r\n"), gbk...)
+
+	testCases := []struct {
+		name    string
+		data    []byte
+		charset string
+		content string
+	}{
+		{"utf8.txt", []byte(content), "UTF-8", content},
+		{"utf8-bom.txt", append([]byte("\xef\xbb\xbf"), content...), "UTF-8", content},
+		{"gbk.txt", gbk, "GB18030", content},
+		{"utf16le.txt", utf16, "UTF-16LE", content},
+		{"mixed.txt", mixed, "GB18030", "密码\r\n" + content},
+	}
+
+	dir := t.TempDir()
+	for _, tc := range testCases {
+		filename := filepath.Join(dir, tc.name)
+		if err := os.WriteFile(filename, tc.data, 0644); err != nil {
+			t.Fatalf("写入测试文件失败: %v", err)
+		}
+
+		text, charset, err := util.ReadTextFile(filename)
+		if err != nil {
+			t.Errorf("%s: 读取失败: %v", tc.name, err)
+			continue
+		}
+		if charset != tc.charset {
+			t.Errorf("%s: 编码期望=%s, 实际=%s", tc.name, tc.charset, charset)
+		}
+		if text != tc.content {
+			t.Errorf("%s: 内容不正确: %q", tc.name, text)
+		}
+	}
+}
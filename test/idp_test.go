@@ -0,0 +1,125 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/cyberspacesec/chrome_auto_login/pkg/config"
+	"github.com/cyberspacesec/chrome_auto_login/pkg/detector"
+	"github.com/cyberspacesec/chrome_auto_login/util"
+)
+
+// TestFingerprintIdPURL 测试根据地址识别统一认证服务
+func TestFingerprintIdPURL(t *testing.T) {
+	testCases := []struct {
+		url     string
+		product string
+		key     string
+	}{
+		{"https://login.microsoftonline.com/contoso.onmicrosoft.com/oauth2/v2.0/authorize?client_id=1", detector.IdPAzureAD, "azure ad://login.microsoftonline.com/contoso.onmicrosoft.com"},
+		{"https://login.microsoftonline.com/", detector.IdPAzureAD, "azure ad://login.microsoftonline.com"},
+		{"https://acme.okta.com/oauth2/v1/authorize?client_id=1", detector.IdPOkta, "okta://acme.okta.com"},
+		{"https://sts.example.com/adfs/ls/?wa=wsignin1.0", detector.IdPADFS, "adfs://sts.example.com"},
+		{"https://sso.example.com/auth/realms/staff/protocol/openid-connect/auth?client_id=oa", detector.IdPKeycloak, "keycloak://sso.example.com/staff"},
+		{"https://id.example.com/realms/master/login-actions/authenticate", detector.IdPKeycloak, "keycloak://id.example.com/master"},
+		{"https://cas.example.edu/cas/login?service=https%3A%2F%2Foa.example.edu%2F", detector.IdPCAS, "cas://cas.example.edu"},
+		{"https://auth.example.edu/sso/cas/login", detector.IdPCAS, "cas://auth.example.edu/sso"},
+		{"https://auth.example.edu/login?service=https%3A%2F%2Fmail.example.edu%2F", detector.IdPCAS, "cas://auth.example.edu"},
+		{"https://www.example.com/login", "", ""},
+		{"https://www.example.com/admin/login.php?next=/", "", ""},
+	}
+
+	for _, tc := range testCases {
+		idp := detector.FingerprintIdPURL(tc.url)
+		if tc.product == "" {
+			if idp != nil {
+				t.Errorf("%s 不应识别为IdP: %+v", tc.url, idp)
+			}
+			continue
+		}
+		if idp == nil {
+			t.Errorf("%s 未识别出IdP", tc.url)
+			continue
+		}
+		if idp.Product != tc.product || idp.Key != tc.key {
+			t.Errorf("%s 识别结果不正确: 期望=%s %s, 实际=%s %s", tc.url, tc.product, tc.key, idp.Product, idp.Key)
+		}
+	}
+}
+
+// TestIdPRegistry 测试共用同一IdP的目标去重
+func TestIdPRegistry(t *testing.T) {
+	registry := detector.NewIdPRegistry()
+	keycloak := detector.FingerprintIdPURL("https://sso.example.com/realms/staff/protocol/openid-connect/auth")
+	cas := detector.FingerprintIdPURL("https://cas.example.edu/cas/login?service=x")
+
+	if first := registry.Register("https://oa.example.com/", keycloak); first != "" {
+		t.Errorf("首次登记不应返回已测试目标: %s", first)
+	}
+	// 首个目标未完成测试（如提前结束）时，共用IdP的目标不应跳过
+	if first := registry.Register("https://mail.example.com/", keycloak); first != "" {
+		t.Errorf("IdP未完成测试时不应去重: %s", first)
+	}
+
+	registry.MarkTested("https://mail.example.com/", keycloak)
+	registry.MarkTested("https://oa.example.com/", keycloak)
+	if first := registry.Register("https://hr.example.com/", keycloak); first != "https://mail.example.com/" {
+		t.Errorf("共用IdP的目标应返回完成测试的目标: %s", first)
+	}
+	if first := registry.Register("https://mail.example.com/", keycloak); first != "" {
+		t.Errorf("重复登记完成测试的目标不应视为重复: %s", first)
+	}
+	if first := registry.Register("https://lib.example.edu/", cas); first != "" {
+		t.Errorf("不同IdP不应去重: %s", first)
+	}
+
+	relations := registry.Relations()
+	if len(relations) != 2 {
+		t.Fatalf("IdP数量不正确: %d", len(relations))
+	}
+	if relations[0].IdP.Product != detector.IdPKeycloak || len(relations[0].Apps) != 3 || relations[0].Tested != "https://mail.example.com/" {
+		t.Errorf("Keycloak关系不正确: %+v", relations[0])
+	}
+	if relations[1].IdP.Product != detector.IdPCAS || len(relations[1].Apps) != 1 || relations[1].Tested != "" {
+		t.Errorf("CAS关系不正确: %+v", relations[1])
+	}
+}
+
+// TestADFSPageFeatures 测试只有userNameInput输入框的页面不会被识别为ADFS
+func TestADFSPageFeatures(t *testing.T) {
+	if testing.Short() {
+		t.Skip("跳过IdP页面特征测试（使用 -short 标志）")
+	}
+
+	browserInstance := startTestBrowser(t)
+	defer browserInstance.Close()
+
+	cfg, err := config.LoadConfig("../config/config.yaml")
+	if err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+	pd := detector.NewPageDetector(browserInstance, cfg, util.Logger)
+
+	testCases := []struct {
+		name    string
+		page    string
+		product string
+	}{
+		{"仅userNameInput", `<form><input id="userNameInput" name="user"><input type="password" name="pwd"><button>登录</button></form>`, ""},
+		{"ADFS表单", `<form id="form1"><input id="userNameInput" name="UserName"><input id="passwordInput" type="password" name="Password">` +
+			`<span id="submitButton" role="button">Sign in</span></form>`, detector.IdPADFS},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := browserInstance.NavigateTo("data:text/html,<html><body>" + tc.page + "</body></html>"); err != nil {
+				t.Fatalf("打开测试页面失败: %v", err)
+			}
+			product := ""
+			if idp := pd.DetectIdentityProvider(); idp != nil {
+				product = idp.Product
+			}
+			if product != tc.product {
+				t.Errorf("识别结果不正确: 期望=%q, 实际=%q", tc.product, product)
+			}
+		})
+	}
+}
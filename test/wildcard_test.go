@@ -0,0 +1,35 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/cyberspacesec/chrome_auto_login/util"
+)
+
+// TestWildcardToRegexp 测试资源拦截白名单的通配符转换
+func TestWildcardToRegexp(t *testing.T) {
+	testCases := []struct {
+		pattern string
+		url     string
+		matched bool
+	}{
+		{"*/captcha*", "https://example.com/captcha.jpg?t=1", true},
+		{"*/captcha*", "https://example.com/logo.png", false},
+		{"*.PNG", "https://example.com/logo.png", true},
+		{"*/img?.png", "https://example.com/img1.png", true},
+		{"*/img?.png", "https://example.com/img12.png", false},
+		{`*/code\?*`, "https://example.com/code?r=1", true},
+		{`*/code\?*`, "https://example.com/codes/1", false},
+		{`*/a\*b`, "https://example.com/a*b", true},
+		{`*/a\*b`, "https://example.com/axxb", false},
+		{"*/a.b", "https://example.com/axb", false},
+		{`*/path\`, `https://example.com/path\`, true},
+		{`*/path\`, "https://example.com/path", false},
+	}
+
+	for _, tc := range testCases {
+		if matched := util.WildcardToRegexp(tc.pattern).MatchString(tc.url); matched != tc.matched {
+			t.Errorf("%s 匹配 %s: 期望=%v, 实际=%v", tc.pattern, tc.url, tc.matched, matched)
+		}
+	}
+}
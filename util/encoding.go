@@ -0,0 +1,173 @@
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// 编码的判定来源
+const (
+	EncodingFromBOM     = "bom"     // 字节顺序标记
+	EncodingFromHeader  = "header"  // HTTP响应头Content-Type
+	EncodingFromMeta    = "meta"    // HTML meta标签
+	EncodingFromBrowser = "browser" // 浏览器实际采用的编码（document.characterSet）
+	EncodingFromContent = "content" // 按字节内容推测
+)
+
+// metaPrescanLimit 查找meta charset时扫描的字节数（与浏览器预扫描一致）
+const metaPrescanLimit = 1024 * 4
+
+var (
+	contentTypeCharsetRe = regexp.MustCompile(`(?i)charset\s*=\s*["']?([^"';\s]+)`)
+	metaCharsetRe        = regexp.MustCompile(`(?i)<meta[^>]+charset\s*=\s*["']?([^"'>;\s/]+)`)
+)
+
+// CharsetFromContentType 从Content-Type中提取charset参数
+func CharsetFromContentType(contentType string) string {
+	if m := contentTypeCharsetRe.FindStringSubmatch(contentType); m != nil {
+		return NormalizeCharset(m[1])
+	}
+	return ""
+}
+
+// NormalizeCharset 把编码标签规范化为标准名称（如 gb2312 -> GBK），不认识的标签返回空字符串
+func NormalizeCharset(label string) string {
+	enc, err := htmlindex.Get(strings.TrimSpace(label))
+	if err != nil {
+		return ""
+	}
+	name, err := htmlindex.Name(enc)
+	if err != nil {
+		return ""
+	}
+	return strings.ToUpper(name)
+}
+
+// DetectEncoding 检测原始字节的编码，依次使用BOM、Content-Type、meta标签和内容特征，返回编码名称和判定来源
+func DetectEncoding(data []byte, contentType string) (string, string) {
+	if charset := bomCharset(data); charset != "" {
+		return charset, EncodingFromBOM
+	}
+	if charset := CharsetFromContentType(contentType); charset != "" {
+		return charset, EncodingFromHeader
+	}
+
+	head := data
+	if len(head) > metaPrescanLimit {
+		head = head[:metaPrescanLimit]
+	}
+	if m := metaCharsetRe.FindSubmatch(head); m != nil {
+		if charset := NormalizeCharset(string(m[1])); charset != "" {
+			return charset, EncodingFromMeta
+		}
+	}
+
+	return guessCharset(data), EncodingFromContent
+}
+
+// DecodeBytes 按指定编码把原始字节转换为UTF-8字符串，会去掉BOM
+func DecodeBytes(data []byte, charset string) (string, error) {
+	enc, err := htmlindex.Get(charset)
+	if err != nil {
+		return "", fmt.Errorf("不支持的编码: %s", charset)
+	}
+	name, _ := htmlindex.Name(enc)
+	if name == "utf-8" {
+		return string(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))), nil
+	}
+
+	// UTF-16按BOM判断字节序，并去掉BOM
+	var decoder transform.Transformer = enc.NewDecoder()
+	if strings.HasPrefix(name, "utf-16") {
+		decoder = unicode.BOMOverride(decoder)
+	}
+
+	decoded, _, err := transform.Bytes(decoder, data)
+	if err != nil {
+		return "", fmt.Errorf("按 %s 解码失败: %v", charset, err)
+	}
+	return string(decoded), nil
+}
+
+// ReadTextFile 读取文本文件并转换为UTF-8，返回内容和检测到的编码。
+// 没有BOM或编码声明时逐行处理：合法UTF-8的行原样保留，只对其余行推测编码，
+// 拼接而成的字典文件中混有不同编码的行时也能正确读取，此时返回的是非UTF-8行的编码
+func ReadTextFile(filename string) (string, string, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return "", "", err
+	}
+
+	charset, source := DetectEncoding(data, "")
+	if source == EncodingFromContent && charset != "UTF-8" {
+		return decodeLines(data)
+	}
+	text, err := DecodeBytes(data, charset)
+	if err != nil {
+		return "", charset, err
+	}
+	return text, charset, nil
+}
+
+// decodeLines 逐行解码，合法UTF-8的行原样保留，其余行按内容推测编码
+func decodeLines(data []byte) (string, string, error) {
+	var builder strings.Builder
+	charset := ""
+	for _, line := range bytes.SplitAfter(data, []byte("\n")) {
+		if utf8.Valid(line) {
+			builder.Write(line)
+			continue
+		}
+
+		lineCharset := guessCharset(line)
+		if charset == "" {
+			charset = lineCharset
+		}
+		text, err := DecodeBytes(line, lineCharset)
+		if err != nil {
+			return "", lineCharset, err
+		}
+		builder.WriteString(text)
+	}
+	return builder.String(), charset, nil
+}
+
+// bomCharset 根据字节顺序标记判断编码
+func bomCharset(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte("\xef\xbb\xbf")):
+		return "UTF-8"
+	case bytes.HasPrefix(data, []byte("\xff\xfe")):
+		return "UTF-16LE"
+	case bytes.HasPrefix(data, []byte("\xfe\xff")):
+		return "UTF-16BE"
+	}
+	return ""
+}
+
+// guessCharset 没有声明编码时按内容推测：合法UTF-8视为UTF-8，能按GB18030完整解码的视为GB18030，否则按Windows-1252处理
+func guessCharset(data []byte) string {
+	if utf8.Valid(data) {
+		return "UTF-8"
+	}
+	if decodesCleanly(simplifiedchinese.GB18030, data) {
+		return "GB18030"
+	}
+	return "WINDOWS-1252"
+}
+
+// decodesCleanly 解码过程中没有出现替换字符
+func decodesCleanly(enc encoding.Encoding, data []byte) bool {
+	decoded, err := enc.NewDecoder().Bytes(data)
+	return err == nil && !bytes.ContainsRune(decoded, utf8.RuneError)
+}
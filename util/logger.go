@@ -1,6 +1,7 @@
 package util
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -176,12 +177,13 @@ type ResultLogger struct {
 	saveDir               string
 	successFilenameFormat string
 	failureFilenameFormat string
+	recordFilenameFormat  string
 	format                string
 	realtimeSave          bool
 }
 
 // NewResultLogger 创建结果记录器
-func NewResultLogger(saveDir, successFormat, failureFormat, format string, realtime bool) *ResultLogger {
+func NewResultLogger(saveDir, successFormat, failureFormat, recordFormat, format string, realtime bool) *ResultLogger {
 	// 创建结果目录
 	_ = os.MkdirAll(saveDir, 0755)
 
@@ -189,11 +191,17 @@ func NewResultLogger(saveDir, successFormat, failureFormat, format string, realt
 		saveDir:               saveDir,
 		successFilenameFormat: successFormat,
 		failureFilenameFormat: failureFormat,
+		recordFilenameFormat:  recordFormat,
 		format:                format,
 		realtimeSave:          realtime,
 	}
 }
 
+// SaveDir 获取结果保存目录
+func (rl *ResultLogger) SaveDir() string {
+	return rl.saveDir
+}
+
 // LogSuccess 记录成功结果
 func (rl *ResultLogger) LogSuccess(url, username, password string) error {
 	if !rl.realtimeSave || rl.successFilenameFormat == "" {
@@ -252,6 +260,32 @@ func (rl *ResultLogger) LogFailure(url, username, password string) error {
 	return err
 }
 
+// LogRecord 以JSON Lines格式记录一条结构化结果
+func (rl *ResultLogger) LogRecord(record interface{}) error {
+	if !rl.realtimeSave || rl.recordFilenameFormat == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	filename := time.Now().Format(rl.recordFilenameFormat)
+	filePath := filepath.Join(rl.saveDir, filename)
+
+	file, err := os.OpenFile(filePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	if err != nil {
+		return err
+	}
+	defer func(file *os.File) {
+		_ = file.Close()
+	}(file)
+
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
+
 // ProgressAwareLogger 支持进度条的日志记录器
 type ProgressAwareLogger struct {
 	statusDisplay *StatusDisplay
@@ -0,0 +1,30 @@
+package util
+
+import (
+	"regexp"
+	"strings"
+)
+
+// WildcardToRegexp 将通配符规则（* 任意字符，? 单个字符，\* 和 \? 表示字面字符）转换为不区分大小写的正则表达式。
+// 末尾单独的 \ 按字面字符处理
+func WildcardToRegexp(pattern string) *regexp.Regexp {
+	var expr strings.Builder
+	expr.WriteString("(?i)^")
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+			}
+			expr.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		case '*':
+			expr.WriteString(".*")
+		case '?':
+			expr.WriteString(".")
+		default:
+			expr.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	expr.WriteString("$")
+	return regexp.MustCompile(expr.String())
+}